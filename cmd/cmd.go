@@ -21,6 +21,9 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/triggermesh/tm/pkg/client"
 	"github.com/triggermesh/tm/pkg/generate"
+	logwrapper "github.com/triggermesh/tm/pkg/log"
+	printerwrapper "github.com/triggermesh/tm/pkg/printer"
+	"github.com/triggermesh/tm/pkg/resources/broker"
 	"github.com/triggermesh/tm/pkg/resources/channel"
 	"github.com/triggermesh/tm/pkg/resources/configuration"
 	"github.com/triggermesh/tm/pkg/resources/credential"
@@ -28,8 +31,10 @@ import (
 	"github.com/triggermesh/tm/pkg/resources/revision"
 	"github.com/triggermesh/tm/pkg/resources/route"
 	"github.com/triggermesh/tm/pkg/resources/service"
+	"github.com/triggermesh/tm/pkg/resources/subscription"
 	"github.com/triggermesh/tm/pkg/resources/task"
 	"github.com/triggermesh/tm/pkg/resources/taskrun"
+	"github.com/triggermesh/tm/pkg/resources/trigger"
 
 	// Required for configs with gcp auth provider
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
@@ -48,6 +53,9 @@ var (
 	registrySkipTLS bool
 
 	c   channel.Channel
+	b   broker.Broker
+	tg  trigger.Trigger
+	sb  subscription.Subscription
 	t   task.Task
 	tr  taskrun.TaskRun
 	plr pipelineresource.PipelineResource
@@ -58,6 +66,7 @@ var (
 	cf  configuration.Configuration
 	gc  credential.GitCreds
 	rc  credential.RegistryCreds
+	cr  credential.Credential
 )
 
 // tmCmd represents the base command when called without any subcommands
@@ -75,7 +84,13 @@ func Execute() {
 }
 
 func init() {
-	cobra.OnInitialize(initConfig)
+	// client is initialized before the command run, so that subcommands
+	// that don't need cluster connection can override it. Cobra runs only
+	// the closest PersistentPreRun, overrides must call initLocalConfig
+	// to get the flags applied to the client
+	tmCmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+		initConfig()
+	}
 	tmCmd.PersistentFlags().StringVar(&kubeConf, "config", "", "k8s config file")
 	tmCmd.PersistentFlags().StringVarP(&client.Namespace, "namespace", "n", "", "User namespace")
 	tmCmd.PersistentFlags().BoolVarP(&debug, "debug", "d", false, "Enable debug output")
@@ -93,6 +108,13 @@ func init() {
 	tmCmd.AddCommand(newPushCmd(&clientset))
 	tmCmd.AddCommand(newSetCmd(&clientset))
 	tmCmd.AddCommand(newGetCmd(&clientset))
+	tmCmd.AddCommand(newRollbackCmd(&clientset))
+	tmCmd.AddCommand(newPlanCmd(&clientset))
+	tmCmd.AddCommand(newLogsCmd(&clientset))
+	tmCmd.AddCommand(newSendCmd(&clientset))
+	tmCmd.AddCommand(newValidateCmd(&clientset))
+	tmCmd.AddCommand(newRunCmd(&clientset))
+	tmCmd.AddCommand(newDevCmd(&clientset))
 }
 
 var versionCmd = &cobra.Command{
@@ -108,6 +130,28 @@ func initConfig() {
 	if clientset, err = client.NewClient(confPath, tmCmd.OutOrStdout()); err != nil {
 		log.Fatalln(err)
 	}
+	setupClient()
+}
+
+// initLocalConfig initializes client for the commands that can work without cluster connection.
+// If there is no cluster configuration, only the logger and the registry settings are set.
+func initLocalConfig() {
+	confPath := client.ConfigPath(kubeConf)
+	if clientset, err = client.NewClient(confPath, tmCmd.OutOrStdout()); err != nil {
+		clientset = client.ConfigSet{
+			Log:      logwrapper.NewLogger(),
+			Printer:  printerwrapper.NewPrinter(tmCmd.OutOrStdout()),
+			Registry: &client.Registry{},
+		}
+		if client.Namespace == "" {
+			client.Namespace = "default"
+		}
+	}
+	setupClient()
+}
+
+// setupClient applies global flags to the initialized client
+func setupClient() {
 	clientset.Printer.Format = client.Output
 	if debug {
 		clientset.Log.SetDebugLevel()
@@ -37,14 +37,20 @@ func newDeleteCmd(clientset *client.ConfigSet) *cobra.Command {
 
 	deleteCmd.Flags().StringVarP(&file, "file", "f", "serverless.yaml", "Delete functions defined in yaml")
 	deleteCmd.Flags().IntVarP(&concurrency, "concurrency", "c", 3, "Number of concurrent deletion threads")
+	deleteCmd.Flags().StringVar(&s.Stage, "stage", "", "Manifest stage overrides to apply")
 	deleteCmd.AddCommand(cmdDeleteConfiguration(clientset))
 	deleteCmd.AddCommand(cmdDeleteRevision(clientset))
 	deleteCmd.AddCommand(cmdDeleteService(clientset))
 	deleteCmd.AddCommand(cmdDeleteRoute(clientset))
 	deleteCmd.AddCommand(cmdDeleteChannel(clientset))
+	deleteCmd.AddCommand(cmdDeleteBroker(clientset))
+	deleteCmd.AddCommand(cmdDeleteTrigger(clientset))
+	deleteCmd.AddCommand(cmdDeleteSubscription(clientset))
 	deleteCmd.AddCommand(cmdDeleteTask(clientset))
 	deleteCmd.AddCommand(cmdDeleteTaskRun(clientset))
 	deleteCmd.AddCommand(cmdDeletePipelineResource(clientset))
+	deleteCmd.AddCommand(cmdDeleteRegistryCreds(clientset))
+	deleteCmd.AddCommand(cmdDeleteGitCreds(clientset))
 
 	return deleteCmd
 }
@@ -66,6 +72,57 @@ func cmdDeleteChannel(clientset *client.ConfigSet) *cobra.Command {
 	}
 }
 
+func cmdDeleteBroker(clientset *client.ConfigSet) *cobra.Command {
+	return &cobra.Command{
+		Use:     "broker",
+		Aliases: []string{"brokers"},
+		Short:   "Delete knative broker resource",
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			b.Name = args[0]
+			b.Namespace = client.Namespace
+			if err := b.Delete(clientset); err != nil {
+				log.Fatalln(err)
+			}
+			clientset.Log.Infoln("Broker is being deleted")
+		},
+	}
+}
+
+func cmdDeleteTrigger(clientset *client.ConfigSet) *cobra.Command {
+	return &cobra.Command{
+		Use:     "trigger",
+		Aliases: []string{"triggers"},
+		Short:   "Delete knative trigger resource",
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			tg.Name = args[0]
+			tg.Namespace = client.Namespace
+			if err := tg.Delete(clientset); err != nil {
+				log.Fatalln(err)
+			}
+			clientset.Log.Infoln("Trigger is being deleted")
+		},
+	}
+}
+
+func cmdDeleteSubscription(clientset *client.ConfigSet) *cobra.Command {
+	return &cobra.Command{
+		Use:     "subscription",
+		Aliases: []string{"subscriptions"},
+		Short:   "Delete knative subscription resource",
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			sb.Name = args[0]
+			sb.Namespace = client.Namespace
+			if err := sb.Delete(clientset); err != nil {
+				log.Fatalln(err)
+			}
+			clientset.Log.Infoln("Subscription is being deleted")
+		},
+	}
+}
+
 func cmdDeleteService(clientset *client.ConfigSet) *cobra.Command {
 	return &cobra.Command{
 		Use:     "service",
@@ -184,3 +241,35 @@ func cmdDeletePipelineResource(clientset *client.ConfigSet) *cobra.Command {
 		},
 	}
 }
+
+func cmdDeleteRegistryCreds(clientset *client.ConfigSet) *cobra.Command {
+	return &cobra.Command{
+		Use:   "registry-auth",
+		Short: "Delete registry credentials secret and remove it from service accounts",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			rc.Name = args[0]
+			rc.Namespace = client.Namespace
+			if err := rc.DeleteRegistryCreds(clientset); err != nil {
+				log.Fatalln(err)
+			}
+			clientset.Log.Infoln("Registry credentials deleted")
+		},
+	}
+}
+
+func cmdDeleteGitCreds(clientset *client.ConfigSet) *cobra.Command {
+	return &cobra.Command{
+		Use:   "git-auth",
+		Short: "Delete git credentials secret and remove it from service accounts",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			gc.Name = args[0]
+			gc.Namespace = client.Namespace
+			if err := gc.DeleteGitCreds(clientset); err != nil {
+				log.Fatalln(err)
+			}
+			clientset.Log.Infoln("Git credentials deleted")
+		},
+	}
+}
@@ -17,6 +17,8 @@ package cmd
 import (
 	"github.com/spf13/cobra"
 	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/resources/service"
+	"github.com/triggermesh/tm/pkg/resources/trigger"
 )
 
 func newDeployCmd(clientset *client.ConfigSet) *cobra.Command {
@@ -37,9 +39,18 @@ func newDeployCmd(clientset *client.ConfigSet) *cobra.Command {
 
 	deployCmd.Flags().StringVarP(&yaml, "from", "f", "serverless.yaml", "Deploy functions defined in yaml")
 	deployCmd.Flags().IntVarP(&concurrency, "concurrency", "c", 3, "Number on concurrent deployment threads")
+	deployCmd.Flags().BoolVar(&s.QuietBuild, "quiet-build", false, "Do not stream image build logs")
+	deployCmd.Flags().BoolVar(&s.ForceBuild, "force-build", false, "Build images even if sources are not changed")
+	deployCmd.Flags().BoolVar(&s.NoDigestResolve, "no-digest-resolve", false, "Deploy images by tag instead of resolving them to digests")
+	deployCmd.Flags().StringVar(&s.Stage, "stage", "", "Manifest stage overrides to apply")
+	deployCmd.Flags().StringVar(&s.ServiceAccount, "service-account", "", "Service account to run functions with. Overrides manifest provider value")
+	deployCmd.Flags().StringVar(&s.BuildServiceAccount, "build-service-account", "", "Service account to build images with. Defaults to the functions service account")
 
 	deployCmd.AddCommand(cmdDeployService(clientset))
 	deployCmd.AddCommand(cmdDeployChannel(clientset))
+	deployCmd.AddCommand(cmdDeployBroker(clientset))
+	deployCmd.AddCommand(cmdDeployTrigger(clientset))
+	deployCmd.AddCommand(cmdDeploySubscription(clientset))
 	deployCmd.AddCommand(cmdDeployTask(clientset))
 	deployCmd.AddCommand(cmdDeployTaskRun(clientset))
 	deployCmd.AddCommand(cmdDeployPipelineResource(clientset))
@@ -47,6 +58,7 @@ func newDeployCmd(clientset *client.ConfigSet) *cobra.Command {
 }
 
 func cmdDeployService(clientset *client.ConfigSet) *cobra.Command {
+	var traffic, tags []string
 	deployServiceCmd := &cobra.Command{
 		Use:     "service",
 		Aliases: []string{"services", "svc"},
@@ -56,6 +68,9 @@ func cmdDeployService(clientset *client.ConfigSet) *cobra.Command {
 		Run: func(cmd *cobra.Command, args []string) {
 			s.Name = args[0]
 			s.Namespace = client.Namespace
+			if s.Traffic, err = service.ParseTraffic(traffic, tags); err != nil {
+				clientset.Log.Fatal(err)
+			}
 			output, err := s.Deploy(clientset)
 			if err != nil {
 				clientset.Log.Fatal(err)
@@ -77,9 +92,24 @@ func cmdDeployService(clientset *client.ConfigSet) *cobra.Command {
 	deployServiceCmd.Flags().StringSliceVar(&s.BuildArgs, "build-argument", []string{}, "Build arguments")
 	deployServiceCmd.Flags().StringSliceVar(&s.EnvSecrets, "env-secret", []string{}, "Name of k8s secrets to populate pod environment variables")
 	deployServiceCmd.Flags().BoolVar(&s.BuildOnly, "build-only", false, "Build image and exit")
+	deployServiceCmd.Flags().BoolVar(&s.QuietBuild, "quiet-build", false, "Do not stream image build logs")
+	deployServiceCmd.Flags().BoolVar(&s.ForceBuild, "force-build", false, "Build image even if sources are not changed")
+	deployServiceCmd.Flags().BoolVar(&s.NoDigestResolve, "no-digest-resolve", false, "Deploy image by tag instead of resolving it to digest")
+	deployServiceCmd.Flags().StringVar(&s.ServiceAccount, "service-account", "", "Service account to run service with")
+	deployServiceCmd.Flags().StringVar(&s.BuildServiceAccount, "build-service-account", "", "Service account to build image with. Defaults to the service account")
 	deployServiceCmd.Flags().StringSliceVarP(&s.Labels, "label", "l", []string{}, "Service labels")
 	deployServiceCmd.Flags().StringToStringVarP(&s.Annotations, "annotation", "a", map[string]string{}, "Revision template annotations")
 	deployServiceCmd.Flags().StringSliceVarP(&s.Env, "env", "e", []string{}, "Environment variables of the service, eg. `--env foo=bar`")
+	deployServiceCmd.Flags().StringToStringVar(&s.Requests, "requests", map[string]string{}, "Container resource requests, eg. `--requests cpu=100m,memory=128Mi`")
+	deployServiceCmd.Flags().StringToStringVar(&s.Limits, "limits", map[string]string{}, "Container resource limits, eg. `--limits cpu=1,memory=512Mi`")
+	deployServiceCmd.Flags().IntVar(&s.MinScale, "min-scale", 0, "Minimum number of service replicas")
+	deployServiceCmd.Flags().IntVar(&s.MaxScale, "max-scale", 0, "Maximum number of service replicas, 0 - no limit")
+	deployServiceCmd.Flags().IntVar(&s.Target, "scale-target", 0, "Autoscaler target number of concurrent requests per replica")
+	deployServiceCmd.Flags().IntVar(&s.TargetUtilization, "scale-utilization", 0, "Autoscaler target utilization percentage")
+	deployServiceCmd.Flags().StringSliceVar(&traffic, "traffic", []string{}, "Traffic split between revisions, eg. `--traffic foo-abcde=90,@latest=10`")
+	deployServiceCmd.Flags().StringSliceVar(&tags, "tag", []string{}, "Revision tags, eg. `--tag @latest=candidate`")
+	deployServiceCmd.Flags().IntSliceVar(&s.Canary.Steps, "canary", []int{}, "Gradually shift traffic to the new revision by given percents, eg. `--canary 10,50`. Requires --wait to perform all steps")
+	deployServiceCmd.Flags().StringVar(&s.Canary.Interval, "canary-interval", "1m", "Time interval between canary steps")
 	return deployServiceCmd
 }
 
@@ -88,7 +118,8 @@ func cmdDeployChannel(clientset *client.ConfigSet) *cobra.Command {
 		Use:     "channel",
 		Aliases: []string{"channels"},
 		Args:    cobra.ExactArgs(1),
-		Short:   "Deploy knative eventing in-memory CRD channel",
+		Short:   "Deploy knative eventing channel",
+		Example: "tm deploy channel foo --kind KafkaChannel --spec '{numPartitions: 3, replicationFactor: 1}'",
 		Run: func(cmd *cobra.Command, args []string) {
 			c.Name = args[0]
 			c.Namespace = client.Namespace
@@ -98,11 +129,79 @@ func cmdDeployChannel(clientset *client.ConfigSet) *cobra.Command {
 		},
 	}
 
-	// only InMemoryChannel kind of channels available now
-	// deployChannelCmd.Flags().StringVarP(&c.Kind, "kind", "k", "InMemoryChannel", "Channel kind")
+	deployChannelCmd.Flags().StringVarP(&c.Kind, "kind", "k", "InMemoryChannel", "Channel implementation kind")
+	deployChannelCmd.Flags().StringVar(&c.APIVersion, "api-version", "", "Channel implementation API version. Required for unknown channel kinds")
+	deployChannelCmd.Flags().StringVar(&c.Spec, "spec", "", "Channel implementation spec in YAML or JSON format")
 	return deployChannelCmd
 }
 
+func cmdDeploySubscription(clientset *client.ConfigSet) *cobra.Command {
+	deploySubscriptionCmd := &cobra.Command{
+		Use:     "subscription",
+		Aliases: []string{"subscriptions"},
+		Args:    cobra.ExactArgs(1),
+		Short:   "Deploy knative channel subscription that delivers channel events to the service",
+		Example: "tm deploy subscription foo --channel events --service bar --reply results",
+		Run: func(cmd *cobra.Command, args []string) {
+			sb.Name = args[0]
+			sb.Namespace = client.Namespace
+			if err := sb.Deploy(clientset); err != nil {
+				clientset.Log.Fatal(err)
+			}
+		},
+	}
+
+	deploySubscriptionCmd.Flags().StringVar(&sb.Channel, "channel", "", "Channel to receive events from")
+	deploySubscriptionCmd.Flags().StringVar(&sb.Service, "service", "", "Knative service to deliver events to")
+	deploySubscriptionCmd.Flags().StringVar(&sb.Reply, "reply", "", "Channel to send service responses to")
+	deploySubscriptionCmd.MarkFlagRequired("channel")
+	deploySubscriptionCmd.MarkFlagRequired("service")
+	return deploySubscriptionCmd
+}
+
+func cmdDeployBroker(clientset *client.ConfigSet) *cobra.Command {
+	return &cobra.Command{
+		Use:     "broker",
+		Aliases: []string{"brokers"},
+		Args:    cobra.ExactArgs(1),
+		Short:   "Deploy knative eventing broker",
+		Run: func(cmd *cobra.Command, args []string) {
+			b.Name = args[0]
+			b.Namespace = client.Namespace
+			if err := b.Deploy(clientset); err != nil {
+				clientset.Log.Fatal(err)
+			}
+		},
+	}
+}
+
+func cmdDeployTrigger(clientset *client.ConfigSet) *cobra.Command {
+	var filter []string
+	deployTriggerCmd := &cobra.Command{
+		Use:     "trigger",
+		Aliases: []string{"triggers"},
+		Args:    cobra.ExactArgs(1),
+		Short:   "Deploy knative eventing trigger that delivers broker events to the service",
+		Example: "tm deploy trigger foo --broker default --filter type=dev.knative.foo --service bar",
+		Run: func(cmd *cobra.Command, args []string) {
+			tg.Name = args[0]
+			tg.Namespace = client.Namespace
+			if tg.Filter, err = trigger.ParseFilter(filter); err != nil {
+				clientset.Log.Fatal(err)
+			}
+			if err := tg.Deploy(clientset); err != nil {
+				clientset.Log.Fatal(err)
+			}
+		},
+	}
+
+	deployTriggerCmd.Flags().StringVar(&tg.Broker, "broker", "default", "Broker to receive events from")
+	deployTriggerCmd.Flags().StringSliceVar(&filter, "filter", []string{}, "Event attributes filter in \"attribute=value\" format")
+	deployTriggerCmd.Flags().StringVar(&tg.Service, "service", "", "Knative service to deliver events to")
+	deployTriggerCmd.MarkFlagRequired("service")
+	return deployTriggerCmd
+}
+
 func cmdDeployTask(clientset *client.ConfigSet) *cobra.Command {
 	deployTaskCmd := &cobra.Command{
 		Use:     "task",
@@ -143,6 +242,9 @@ func cmdDeployTaskRun(clientset *client.ConfigSet) *cobra.Command {
 	deployTaskRunCmd.Flags().StringVarP(&tr.PipelineResource.Name, "resources", "r", "", "Name of pipelineresource to pass into task")
 	// deployTaskRunCmd.Flags().StringVarP(&tr.RegistrySecret, "secret", "s", "", "Secret name with registry credentials")
 	deployTaskRunCmd.Flags().StringArrayVar(&tr.Params, "args", []string{}, "Image build arguments")
+	deployTaskRunCmd.Flags().BoolVar(&tr.Quiet, "quiet", false, "Do not stream taskrun logs while waiting for the result")
+	deployTaskRunCmd.Flags().StringVar(&tr.ServiceAccount, "service-account", "", "Service account to run taskrun with")
+	deployTaskRunCmd.Flags().BoolVar(&tr.ForceBuild, "force-build", false, "Run taskrun even if the image built from the same sources exists")
 	return deployTaskRunCmd
 }
 
@@ -0,0 +1,45 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/triggermesh/tm/pkg/client"
+)
+
+func newDevCmd(clientset *client.ConfigSet) *cobra.Command {
+	var cleanup bool
+	devCmd := &cobra.Command{
+		Use:     "dev",
+		Short:   "Deploy functions and redeploy them on local sources change",
+		Args:    cobra.NoArgs,
+		Example: "tm dev -f serverless.yaml --cleanup",
+		Run: func(cmd *cobra.Command, args []string) {
+			s.Namespace = client.Namespace
+			// new URL is printed after the service is ready
+			client.Wait = true
+			if err := s.Dev(yaml, cleanup, concurrency, clientset); err != nil {
+				clientset.Log.Fatal(err)
+			}
+		},
+	}
+
+	devCmd.Flags().StringVarP(&yaml, "from", "f", "serverless.yaml", "Functions yaml manifest")
+	devCmd.Flags().IntVarP(&concurrency, "concurrency", "c", 3, "Number on concurrent deployment threads")
+	devCmd.Flags().BoolVar(&s.QuietBuild, "quiet-build", false, "Do not stream image build logs")
+	devCmd.Flags().StringVar(&s.Stage, "stage", "", "Manifest stage overrides to apply")
+	devCmd.Flags().BoolVar(&cleanup, "cleanup", false, "Delete deployed functions on exit")
+	return devCmd
+}
@@ -21,6 +21,8 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/resources/event"
+	corev1 "k8s.io/api/core/v1"
 )
 
 var (
@@ -41,13 +43,28 @@ func newGetCmd(clientset *client.ConfigSet) *cobra.Command {
 	getCmd.AddCommand(cmdListRoute(clientset))
 	getCmd.AddCommand(cmdListService(clientset))
 	getCmd.AddCommand(cmdListChannels(clientset))
+	getCmd.AddCommand(cmdListBrokers(clientset))
+	getCmd.AddCommand(cmdListTriggers(clientset))
+	getCmd.AddCommand(cmdListSubscriptions(clientset))
 	getCmd.AddCommand(cmdListTasks(clientset))
 	getCmd.AddCommand(cmdListTaskRuns(clientset))
 	getCmd.AddCommand(cmdListPipelineResources(clientset))
+	getCmd.AddCommand(cmdListCredentials(clientset))
 
 	return getCmd
 }
 
+// printEvents writes events section of the object description
+func printEvents(clientset *client.ConfigSet, events []corev1.Event) {
+	fmt.Fprintln(clientset.Printer.Output, "Events:")
+	if len(events) == 0 {
+		fmt.Fprintln(clientset.Printer.Output, "  <none>")
+		return
+	}
+	var e event.Event
+	clientset.Printer.PrintTable(e.GetTable(events))
+}
+
 func cmdListChannels(clientset *client.ConfigSet) *cobra.Command {
 	return &cobra.Command{
 		Use:     "channel",
@@ -77,6 +94,96 @@ func cmdListChannels(clientset *client.ConfigSet) *cobra.Command {
 	}
 }
 
+func cmdListBrokers(clientset *client.ConfigSet) *cobra.Command {
+	return &cobra.Command{
+		Use:     "broker",
+		Aliases: []string{"brokers"},
+		Short:   "List of knative broker resources",
+		Args:    cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			b.Namespace = client.Namespace
+			if len(args) == 0 {
+				list, err := b.List(clientset)
+				if err != nil {
+					clientset.Log.Fatalln(err)
+				}
+				if len(list.Items) == 0 {
+					fmt.Fprintf(cmd.OutOrStdout(), "No brokers found\n")
+					return
+				}
+				clientset.Printer.PrintTable(b.GetTable(list))
+				return
+			}
+			b.Name = args[0]
+			broker, err := b.Get(clientset)
+			if err != nil {
+				clientset.Log.Fatalln(err)
+			}
+			clientset.Printer.PrintObject(b.GetObject(broker))
+		},
+	}
+}
+
+func cmdListTriggers(clientset *client.ConfigSet) *cobra.Command {
+	return &cobra.Command{
+		Use:     "trigger",
+		Aliases: []string{"triggers"},
+		Short:   "List of knative trigger resources",
+		Args:    cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			tg.Namespace = client.Namespace
+			if len(args) == 0 {
+				list, err := tg.List(clientset)
+				if err != nil {
+					clientset.Log.Fatalln(err)
+				}
+				if len(list.Items) == 0 {
+					fmt.Fprintf(cmd.OutOrStdout(), "No triggers found\n")
+					return
+				}
+				clientset.Printer.PrintTable(tg.GetTable(list))
+				return
+			}
+			tg.Name = args[0]
+			trigger, err := tg.Get(clientset)
+			if err != nil {
+				clientset.Log.Fatalln(err)
+			}
+			clientset.Printer.PrintObject(tg.GetObject(trigger))
+		},
+	}
+}
+
+func cmdListSubscriptions(clientset *client.ConfigSet) *cobra.Command {
+	return &cobra.Command{
+		Use:     "subscription",
+		Aliases: []string{"subscriptions"},
+		Short:   "List of knative subscription resources",
+		Args:    cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			sb.Namespace = client.Namespace
+			if len(args) == 0 {
+				list, err := sb.List(clientset)
+				if err != nil {
+					clientset.Log.Fatalln(err)
+				}
+				if len(list.Items) == 0 {
+					fmt.Fprintf(cmd.OutOrStdout(), "No subscriptions found\n")
+					return
+				}
+				clientset.Printer.PrintTable(sb.GetTable(list))
+				return
+			}
+			sb.Name = args[0]
+			subscription, err := sb.Get(clientset)
+			if err != nil {
+				clientset.Log.Fatalln(err)
+			}
+			clientset.Printer.PrintObject(sb.GetObject(subscription))
+		},
+	}
+}
+
 func cmdListService(clientset *client.ConfigSet) *cobra.Command {
 	return &cobra.Command{
 		Use:     "service",
@@ -103,6 +210,14 @@ func cmdListService(clientset *client.ConfigSet) *cobra.Command {
 				clientset.Log.Fatalln(err)
 			}
 			clientset.Printer.PrintObject(s.GetObject(service))
+			if clientset.Printer.Format == "" {
+				events, err := s.Events(service, clientset)
+				if err != nil {
+					clientset.Log.Warnf("Cannot get service events: %s", err)
+					return
+				}
+				printEvents(clientset, events)
+			}
 		},
 	}
 }
@@ -137,7 +252,7 @@ func cmdListConfigurations(clientset *client.ConfigSet) *cobra.Command {
 }
 
 func cmdListRevision(clientset *client.ConfigSet) *cobra.Command {
-	return &cobra.Command{
+	listRevisionCmd := &cobra.Command{
 		Use:     "revision",
 		Aliases: []string{"revisions"},
 		Short:   "List of knative revision resources",
@@ -163,6 +278,8 @@ func cmdListRevision(clientset *client.ConfigSet) *cobra.Command {
 			clientset.Printer.PrintObject(r.GetObject(revision))
 		},
 	}
+	listRevisionCmd.Flags().StringVar(&r.Service, "service", "", "List revisions of the service")
+	return listRevisionCmd
 }
 
 func cmdListRoute(clientset *client.ConfigSet) *cobra.Command {
@@ -236,6 +353,14 @@ func cmdListTaskRuns(clientset *client.ConfigSet) *cobra.Command {
 				clientset.Log.Fatalln(err)
 			}
 			clientset.Printer.PrintObject(tr.GetObject(taskrun))
+			if clientset.Printer.Format == "" {
+				events, err := tr.Events(taskrun, clientset)
+				if err != nil {
+					clientset.Log.Warnf("Cannot get taskrun events: %s", err)
+					return
+				}
+				printEvents(clientset, events)
+			}
 		},
 	}
 }
@@ -264,3 +389,33 @@ func cmdListPipelineResources(clientset *client.ConfigSet) *cobra.Command {
 		},
 	}
 }
+
+func cmdListCredentials(clientset *client.ConfigSet) *cobra.Command {
+	return &cobra.Command{
+		Use:     "credentials",
+		Aliases: []string{"credential", "creds"},
+		Short:   "List of registry and git credentials created by tm",
+		Args:    cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			cr.Namespace = client.Namespace
+			if len(args) == 0 {
+				list, err := cr.List(clientset)
+				if err != nil {
+					clientset.Log.Fatalln(err)
+				}
+				if len(list) == 0 {
+					fmt.Fprintf(cmd.OutOrStdout(), "No credentials found\n")
+					return
+				}
+				clientset.Printer.PrintTable(cr.GetTable(list))
+				return
+			}
+			cr.Name = args[0]
+			credential, err := cr.Get(clientset)
+			if err != nil {
+				clientset.Log.Fatalln(err)
+			}
+			clientset.Printer.PrintObject(cr.GetObject(credential))
+		},
+	}
+}
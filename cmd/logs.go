@@ -0,0 +1,121 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/triggermesh/tm/pkg/client"
+	corev1 "k8s.io/api/core/v1"
+)
+
+type logsOptions struct {
+	follow bool
+	since  time.Duration
+	tail   int64
+}
+
+func (o *logsOptions) podLogOptions() *corev1.PodLogOptions {
+	options := &corev1.PodLogOptions{
+		Follow: o.follow,
+	}
+	if o.since > 0 {
+		seconds := int64(o.since.Seconds())
+		options.SinceSeconds = &seconds
+	}
+	if o.tail >= 0 {
+		options.TailLines = &o.tail
+	}
+	return options
+}
+
+func (o *logsOptions) addFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVarP(&o.follow, "follow", "f", false, "Stream logs until interrupted")
+	cmd.Flags().DurationVar(&o.since, "since", 0, "Only return logs newer than a relative duration like 5s, 2m, or 3h")
+	cmd.Flags().Int64Var(&o.tail, "tail", -1, "Number of recent log lines to show, all lines if negative")
+}
+
+func newLogsCmd(clientset *client.ConfigSet) *cobra.Command {
+	logsCmd := &cobra.Command{
+		Use:   "logs",
+		Short: "Print resource logs",
+	}
+	logsCmd.AddCommand(cmdLogsService(clientset))
+	logsCmd.AddCommand(cmdLogsRevision(clientset))
+	logsCmd.AddCommand(cmdLogsTaskRun(clientset))
+	return logsCmd
+}
+
+func cmdLogsService(clientset *client.ConfigSet) *cobra.Command {
+	var options logsOptions
+	var revision string
+	logsServiceCmd := &cobra.Command{
+		Use:     "service",
+		Aliases: []string{"services", "svc"},
+		Short:   "Print knative service logs",
+		Args:    cobra.ExactArgs(1),
+		Example: "tm logs service foo --follow --since 10m",
+		Run: func(cmd *cobra.Command, args []string) {
+			s.Name = args[0]
+			s.Namespace = client.Namespace
+			if err := s.Logs(revision, clientset, options.podLogOptions(), os.Stdout); err != nil {
+				clientset.Log.Fatal(err)
+			}
+		},
+	}
+	options.addFlags(logsServiceCmd)
+	logsServiceCmd.Flags().StringVar(&revision, "revision", "", "Revision name to print logs of. Latest ready revision is used if not set")
+	return logsServiceCmd
+}
+
+func cmdLogsRevision(clientset *client.ConfigSet) *cobra.Command {
+	var options logsOptions
+	logsRevisionCmd := &cobra.Command{
+		Use:     "revision",
+		Aliases: []string{"revisions"},
+		Short:   "Print knative revision logs",
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			r.Name = args[0]
+			r.Namespace = client.Namespace
+			if err := r.Logs(clientset, options.podLogOptions(), os.Stdout); err != nil {
+				clientset.Log.Fatal(err)
+			}
+		},
+	}
+	options.addFlags(logsRevisionCmd)
+	return logsRevisionCmd
+}
+
+func cmdLogsTaskRun(clientset *client.ConfigSet) *cobra.Command {
+	var options logsOptions
+	logsTaskRunCmd := &cobra.Command{
+		Use:     "taskrun",
+		Aliases: []string{"taskruns"},
+		Short:   "Print tekton taskrun steps logs",
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			tr.Name = args[0]
+			tr.Namespace = client.Namespace
+			if err := tr.Logs(clientset, options.podLogOptions(), os.Stdout); err != nil {
+				clientset.Log.Fatal(err)
+			}
+		},
+	}
+	options.addFlags(logsTaskRunCmd)
+	return logsTaskRunCmd
+}
@@ -0,0 +1,39 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/triggermesh/tm/pkg/client"
+)
+
+func newPlanCmd(clientset *client.ConfigSet) *cobra.Command {
+	planCmd := &cobra.Command{
+		Use:     "plan",
+		Short:   "Show changes that deployment of functions defined in yaml would make",
+		Example: "tm plan -f serverless.yaml",
+		Run: func(cmd *cobra.Command, args []string) {
+			s.Namespace = client.Namespace
+			if err := s.PlanYAML(yaml, args, clientset); err != nil {
+				clientset.Log.Fatal(err)
+			}
+		},
+	}
+
+	planCmd.Flags().StringVarP(&yaml, "from", "f", "serverless.yaml", "Functions yaml manifest")
+	planCmd.Flags().StringVar(&s.Stage, "stage", "", "Manifest stage overrides to apply")
+	planCmd.Flags().BoolVar(&s.NoDigestResolve, "no-digest-resolve", false, "Compare images by tag instead of resolving them to digests")
+	return planCmd
+}
@@ -0,0 +1,51 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/triggermesh/tm/pkg/client"
+)
+
+func newRollbackCmd(clientset *client.ConfigSet) *cobra.Command {
+	rollbackCmd := &cobra.Command{
+		Use:   "rollback",
+		Short: "Route traffic to previous revisions",
+	}
+	rollbackCmd.AddCommand(cmdRollbackService(clientset))
+	return rollbackCmd
+}
+
+func cmdRollbackService(clientset *client.ConfigSet) *cobra.Command {
+	var target string
+	rollbackServiceCmd := &cobra.Command{
+		Use:     "service",
+		Aliases: []string{"services", "svc"},
+		Short:   "Route all knative service traffic to the previous or specified revision",
+		Args:    cobra.ExactArgs(1),
+		Example: "tm rollback service foo --to foo-abcde --wait",
+		Run: func(cmd *cobra.Command, args []string) {
+			s.Name = args[0]
+			s.Namespace = client.Namespace
+			output, err := s.Rollback(target, clientset)
+			if err != nil {
+				clientset.Log.Fatal(err)
+			}
+			clientset.Log.Infoln(output)
+		},
+	}
+	rollbackServiceCmd.Flags().StringVar(&target, "to", "", "Revision name to route traffic to. Previous ready revision is used if not set")
+	return rollbackServiceCmd
+}
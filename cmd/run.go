@@ -0,0 +1,51 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/resources/service"
+)
+
+func newRunCmd(clientset *client.ConfigSet) *cobra.Command {
+	var options service.LocalRun
+	runCmd := &cobra.Command{
+		Use:   "run <function>",
+		Short: "Run yaml manifest function on the local machine",
+		Args:  cobra.ExactArgs(1),
+		Example: `tm run bar -f serverless.yaml --port 8080
+tm run bar -f serverless.yaml --no-container`,
+		// prebuilt images and Go sources can run without cluster connection
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			initLocalConfig()
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Function = args[0]
+			s.Namespace = client.Namespace
+			if err := s.RunLocal(yaml, options, clientset); err != nil {
+				clientset.Log.Fatal(err)
+			}
+		},
+	}
+
+	runCmd.Flags().StringVarP(&yaml, "from", "f", "serverless.yaml", "Functions yaml manifest")
+	runCmd.Flags().StringVar(&s.Stage, "stage", "", "Manifest stage overrides to apply")
+	runCmd.Flags().StringVar(&options.EnvFile, "env-file", ".env", "File with env-secrets values in NAME=VALUE format")
+	runCmd.Flags().IntVarP(&options.Port, "port", "p", 8080, "Local port to expose the function on")
+	runCmd.Flags().StringVar(&options.Engine, "engine", "docker", "Container engine command, e.g. docker or podman")
+	runCmd.Flags().BoolVar(&options.NoContainer, "no-container", false, "Run Go function from the sources without container")
+	return runCmd
+}
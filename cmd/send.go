@@ -0,0 +1,61 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/send"
+)
+
+func newSendCmd(clientset *client.ConfigSet) *cobra.Command {
+	sendCmd := &cobra.Command{
+		Use:   "send",
+		Short: "Send data to the cluster resources",
+	}
+	sendCmd.AddCommand(cmdSendEvent(clientset))
+	return sendCmd
+}
+
+func cmdSendEvent(clientset *client.ConfigSet) *cobra.Command {
+	var e send.Event
+	sendEventCmd := &cobra.Command{
+		Use:     "event",
+		Aliases: []string{"events"},
+		Short:   "Send CloudEvent to the service, broker or URL and print the reply",
+		Args:    cobra.NoArgs,
+		Example: "tm send event --to service/foo --type dev.example.foo --source tm --data @event.json",
+		Run: func(cmd *cobra.Command, args []string) {
+			e.Namespace = client.Namespace
+			if err := e.Send(clientset, os.Stdout); err != nil {
+				clientset.Log.Fatal(err)
+			}
+		},
+	}
+
+	sendEventCmd.Flags().StringVar(&e.Target, "to", "", "Event destination: service/<name>, broker/<name> or URL")
+	sendEventCmd.Flags().StringVar(&e.Type, "type", "", "Event type")
+	sendEventCmd.Flags().StringVar(&e.Source, "source", "tm", "Event source")
+	sendEventCmd.Flags().StringVar(&e.Data, "data", "", "Event data. Use @ prefix to read data from the file")
+	sendEventCmd.Flags().StringVar(&e.ContentType, "content-type", "application/json", "Event data content type")
+	sendEventCmd.Flags().BoolVar(&e.Structured, "structured", false, "Send event in structured content mode instead of binary")
+	sendEventCmd.Flags().IntVar(&e.Count, "count", 1, "Number of events to send")
+	sendEventCmd.Flags().Float64Var(&e.Rate, "rate", 0, "Number of events sent per second, unlimited if not set")
+	sendEventCmd.MarkFlagRequired("to")
+	sendEventCmd.MarkFlagRequired("type")
+	return sendEventCmd
+}
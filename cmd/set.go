@@ -19,6 +19,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/resources/credential"
 )
 
 // setCmd represents the set command
@@ -54,14 +55,25 @@ func cmdSetRegistryCreds(clientset *client.ConfigSet) *cobra.Command {
 	setRegistryCredsCmd.Flags().StringVar(&rc.Password, "password", "", "Registry password")
 	setRegistryCredsCmd.Flags().BoolVar(&rc.Pull, "pull", false, "Indicates if this token must be used for pull operations only")
 	setRegistryCredsCmd.Flags().BoolVar(&rc.Push, "push", false, "Indicates if this token must be used for push operations only")
+	setRegistryCredsCmd.Flags().BoolVar(&rc.Append, "append", false, "Add registry to the existing secret instead of replacing its credentials")
+	setRegistryCredsCmd.Flags().StringVar(&rc.FromDockerConfig, "from-docker-config", "", "Import credentials from local docker config. Use --registry to import only one registry")
+	setRegistryCredsCmd.Flags().Lookup("from-docker-config").NoOptDefVal = credential.DefaultDockerConfig()
+	setRegistryCredsCmd.Flags().StringVar(&rc.PushRegistry, "push-registry", "", "Registry host to build image names from if secret has multiple registries. Defaults to the first registry added")
+	setRegistryCredsCmd.Flags().StringVar(&rc.ServiceAccount, "service-account", "default", "Service account to attach pull credentials to")
 	return setRegistryCredsCmd
 }
 
 func cmdSetGitCreds(clientset *client.ConfigSet) *cobra.Command {
 	setGitCredsCmd := &cobra.Command{
-		Use:   "git-auth",
-		Short: "Create secret with git credentials",
+		Use:   "git-auth [name]",
+		Short: "Create secret with git SSH key or HTTPS credentials",
+		Args:  cobra.MaximumNArgs(1),
+		Example: `tm set git-auth github --host github.com --key ~/.ssh/id_rsa --known-hosts ~/.ssh/known_hosts
+tm set git-auth gitlab --host gitlab.com --username foo --password $TOKEN --service-account builder`,
 		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) == 1 {
+				gc.Name = args[0]
+			}
 			gc.Namespace = client.Namespace
 			if err := gc.CreateGitCreds(clientset); err != nil {
 				log.Fatalln(err)
@@ -70,8 +82,11 @@ func cmdSetGitCreds(clientset *client.ConfigSet) *cobra.Command {
 		},
 	}
 
-	// Use one ssh key for all git sources for now
-	// setGitCredsCmd.Flags().StringVar(&g.Host, "host", "", "Git host address")
-	// setGitCredsCmd.Flags().StringVar(&g.Key, "key", "", "SSH private key")
+	setGitCredsCmd.Flags().StringVar(&gc.Host, "host", "github.com", "Git server host")
+	setGitCredsCmd.Flags().StringVar(&gc.Key, "key", "", "SSH private key file. Key is read from stdin if neither key nor HTTPS credentials are set")
+	setGitCredsCmd.Flags().StringVar(&gc.KnownHosts, "known-hosts", "", "SSH known_hosts file")
+	setGitCredsCmd.Flags().StringVar(&gc.Username, "username", "", "HTTPS username")
+	setGitCredsCmd.Flags().StringVar(&gc.Password, "password", "", "HTTPS password or access token")
+	setGitCredsCmd.Flags().StringVar(&gc.ServiceAccount, "service-account", "default", "Service account to attach credentials to")
 	return setGitCredsCmd
 }
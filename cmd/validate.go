@@ -0,0 +1,64 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/file"
+)
+
+func newValidateCmd(clientset *client.ConfigSet) *cobra.Command {
+	var schema bool
+	validateCmd := &cobra.Command{
+		Use:     "validate",
+		Short:   "Check functions yaml manifest and the manifests it includes",
+		Args:    cobra.NoArgs,
+		Example: "tm validate -f serverless.yaml",
+		// manifest validation does not need cluster connection
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			initLocalConfig()
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			if schema {
+				data, err := file.JSONSchema()
+				if err != nil {
+					clientset.Log.Fatal(err)
+				}
+				fmt.Println(string(data))
+				return
+			}
+			problems, err := s.ValidateYAML(yaml)
+			if err != nil {
+				clientset.Log.Fatal(err)
+			}
+			for _, problem := range problems {
+				fmt.Println(problem)
+			}
+			if len(problems) != 0 {
+				fmt.Printf("%d problems found\n", len(problems))
+				os.Exit(1)
+			}
+			fmt.Println("Manifest is valid")
+		},
+	}
+
+	validateCmd.Flags().StringVarP(&yaml, "from", "f", "serverless.yaml", "Functions yaml manifest")
+	validateCmd.Flags().BoolVar(&schema, "schema", false, "Print manifest JSON Schema")
+	return validateCmd
+}
@@ -69,13 +69,13 @@ type Registry struct {
 
 // ConfigSet contains different information that may be needed by underlying functions
 type ConfigSet struct {
-	Core            *kubernetes.Clientset
-	Serving         *servingApi.Clientset
-	Eventing        *eventingApi.Clientset
-	GithubSource    *githubSource.Clientset
-	TektonPipelines *tektonResource.Clientset
-	TektonTasks     *tektonTask.Clientset
-	TektonTriggers  *triggersApi.Clientset
+	Core            kubernetes.Interface
+	Serving         servingApi.Interface
+	Eventing        eventingApi.Interface
+	GithubSource    githubSource.Interface
+	TektonPipelines tektonResource.Interface
+	TektonTasks     tektonTask.Interface
+	TektonTriggers  triggersApi.Interface
 	Registry        *Registry
 	Log             *logwrapper.StandardLogger
 	Printer         *printerwrapper.Printer
@@ -0,0 +1,124 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fake provides client.ConfigSet backed by generated fake clientsets
+// so that resource operations can be tested without k8s cluster.
+package fake
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	tektonTaskFake "github.com/tektoncd/pipeline/pkg/client/clientset/versioned/fake"
+	tektonResourceFake "github.com/tektoncd/pipeline/pkg/client/resource/clientset/versioned/fake"
+	triggersFake "github.com/tektoncd/triggers/pkg/client/clientset/versioned/fake"
+	"github.com/triggermesh/tm/pkg/client"
+	logwrapper "github.com/triggermesh/tm/pkg/log"
+	printerwrapper "github.com/triggermesh/tm/pkg/printer"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	k8sFake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+	githubSourceFake "knative.dev/eventing-contrib/github/pkg/client/clientset/versioned/fake"
+	eventingFake "knative.dev/eventing/pkg/client/clientset/versioned/fake"
+	servingFake "knative.dev/serving/pkg/client/clientset/versioned/fake"
+)
+
+// Namespace is the namespace that fake ConfigSet is supposed to work in
+const Namespace = "test-namespace"
+
+type clientset struct {
+	addToScheme func(*runtime.Scheme) error
+	objects     []runtime.Object
+}
+
+// NewConfigSet returns ConfigSet with fake clientsets for every supported API.
+// Objects are distributed between clientsets according to their types.
+// Package level client flags are reset to their defaults so that tests
+// do not inherit dry-run or wait mode from the tests that ran before.
+// It panics if object type is not registered in any of the clientsets schemes.
+func NewConfigSet(objects ...runtime.Object) *client.ConfigSet {
+	client.Dry = false
+	client.Wait = false
+	client.Output = ""
+
+	core := &clientset{addToScheme: k8sFake.AddToScheme}
+	serving := &clientset{addToScheme: servingFake.AddToScheme}
+	eventing := &clientset{addToScheme: eventingFake.AddToScheme}
+	githubSource := &clientset{addToScheme: githubSourceFake.AddToScheme}
+	tektonPipelines := &clientset{addToScheme: tektonResourceFake.AddToScheme}
+	tektonTasks := &clientset{addToScheme: tektonTaskFake.AddToScheme}
+	tektonTriggers := &clientset{addToScheme: triggersFake.AddToScheme}
+
+	clientsets := []*clientset{core, serving, eventing, githubSource, tektonPipelines, tektonTasks, tektonTriggers}
+	for _, object := range objects {
+		if !distribute(object, clientsets) {
+			panic(fmt.Sprintf("fake clientset for %T not found", object))
+		}
+	}
+
+	printer := printerwrapper.NewPrinter(ioutil.Discard)
+	return &client.ConfigSet{
+		Core:            k8sFake.NewSimpleClientset(core.objects...),
+		Serving:         servingFake.NewSimpleClientset(serving.objects...),
+		Eventing:        eventingFake.NewSimpleClientset(eventing.objects...),
+		GithubSource:    githubSourceFake.NewSimpleClientset(githubSource.objects...),
+		TektonPipelines: tektonResourceFake.NewSimpleClientset(tektonPipelines.objects...),
+		TektonTasks:     tektonTaskFake.NewSimpleClientset(tektonTasks.objects...),
+		TektonTriggers:  triggersFake.NewSimpleClientset(tektonTriggers.objects...),
+		Registry: &client.Registry{
+			Host: "knative.registry.svc.cluster.local",
+		},
+		Log:     logwrapper.NewLogger(),
+		Printer: printer,
+		Config:  &rest.Config{},
+	}
+}
+
+func distribute(object runtime.Object, clientsets []*clientset) bool {
+	for _, c := range clientsets {
+		scheme := runtime.NewScheme()
+		if err := c.addToScheme(scheme); err != nil {
+			panic(err)
+		}
+		if _, _, err := scheme.ObjectKinds(object); err == nil {
+			c.objects = append(c.objects, object)
+			return true
+		}
+	}
+	return false
+}
+
+// watchReactor is implemented by every generated fake clientset
+type watchReactor interface {
+	PrependWatchReactor(resource string, reaction k8stesting.WatchReactionFunc)
+}
+
+// ScriptWatch makes watch requests for the resource in the fake clientset
+// return the given sequence of events instead of the object tracker updates.
+// Clientset must be one of the ConfigSet fields created by NewConfigSet.
+func ScriptWatch(clientset interface{}, resource string, events ...watch.Event) {
+	reactor, ok := clientset.(watchReactor)
+	if !ok {
+		panic(fmt.Sprintf("%T is not a fake clientset", clientset))
+	}
+	reactor.PrependWatchReactor(resource, func(action k8stesting.Action) (bool, watch.Interface, error) {
+		w := watch.NewFakeWithChanSize(len(events), false)
+		for _, event := range events {
+			w.Action(event.Type, event.Object)
+		}
+		return true, w, nil
+	})
+}
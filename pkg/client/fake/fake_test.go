@@ -0,0 +1,44 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fake
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
+)
+
+func TestFakeConfigSet(t *testing.T) {
+	meta := metav1.ObjectMeta{Name: "foo", Namespace: Namespace}
+	clientset := NewConfigSet(
+		&corev1.Pod{ObjectMeta: meta},
+		&servingv1.Service{ObjectMeta: meta},
+		&v1beta1.TaskRun{ObjectMeta: meta},
+	)
+
+	_, err := clientset.Core.CoreV1().Pods(Namespace).Get("foo", metav1.GetOptions{})
+	require.NoError(t, err)
+	_, err = clientset.Serving.ServingV1().Services(Namespace).Get("foo", metav1.GetOptions{})
+	require.NoError(t, err)
+	_, err = clientset.TektonTasks.TektonV1beta1().TaskRuns(Namespace).Get("foo", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	assert.Panics(t, func() { NewConfigSet(&metav1.Status{}) })
+}
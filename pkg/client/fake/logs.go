@@ -0,0 +1,86 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fake
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/triggermesh/tm/pkg/client"
+	corev1 "k8s.io/api/core/v1"
+	k8sFake "k8s.io/client-go/kubernetes/fake"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+)
+
+// ScriptLogs makes pod logs requests in the fake core clientset return the given logs.
+// Logs are keyed by "pod/container", requests of the containers without logs fail.
+// Generated fake clientset returns logs request without a client that cannot be streamed.
+func ScriptLogs(clientset *client.ConfigSet, logs map[string]string) {
+	core, ok := clientset.Core.(*k8sFake.Clientset)
+	if !ok {
+		panic(fmt.Sprintf("%T is not a fake core clientset", clientset.Core))
+	}
+	clientset.Core = &logsClientset{Clientset: core, logs: logs}
+}
+
+type logsClientset struct {
+	*k8sFake.Clientset
+	logs map[string]string
+}
+
+func (c *logsClientset) CoreV1() corev1client.CoreV1Interface {
+	return &logsCoreV1{CoreV1Interface: c.Clientset.CoreV1(), logs: c.logs}
+}
+
+type logsCoreV1 struct {
+	corev1client.CoreV1Interface
+	logs map[string]string
+}
+
+func (c *logsCoreV1) Pods(namespace string) corev1client.PodInterface {
+	return &logsPods{PodInterface: c.CoreV1Interface.Pods(namespace), logs: c.logs}
+}
+
+type logsPods struct {
+	corev1client.PodInterface
+	logs map[string]string
+}
+
+func (p *logsPods) GetLogs(name string, opts *corev1.PodLogOptions) *rest.Request {
+	// fake clientset records the action
+	p.PodInterface.GetLogs(name, opts)
+	key := name + "/" + opts.Container
+	httpClient := &http.Client{Transport: roundTripper(func(*http.Request) (*http.Response, error) {
+		logs, ok := p.logs[key]
+		if !ok {
+			return nil, fmt.Errorf("%s logs not found", key)
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader(logs)),
+		}, nil
+	})}
+	return rest.NewRequestWithClient(&url.URL{Scheme: "https", Host: "localhost"}, "", rest.ClientContentConfig{}, httpClient).Verb("GET")
+}
+
+type roundTripper func(*http.Request) (*http.Response, error)
+
+func (f roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
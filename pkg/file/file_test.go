@@ -14,6 +14,17 @@ func TestParseManifest(t *testing.T) {
 
 	assert.Equal(t, "serverless-foo", definition.Service)
 	assert.Equal(t, "serverless.yaml parsing test", definition.Description)
+	assert.Equal(t, "256Mi", definition.Provider.Resources.Limits["memory"])
+	assert.Equal(t, "100m", definition.Functions["bar"].Resources.Requests["cpu"])
+	assert.Equal(t, 3, definition.Functions["bar"].Scaling.MaxScale)
+	assert.Equal(t, []Event{{Type: "dev.triggermesh.bar", Filter: map[string]string{"source": "foo"}}}, definition.Functions["bar"].Events)
+	assert.Equal(t, []Subscription{{Channel: "bar-input", Reply: "bar-output"}}, definition.Functions["bar"].Subscriptions)
+	assert.Equal(t, []Source{{GitHub: &GitHubSource{
+		Repository:  "triggermesh/tm",
+		EventTypes:  []string{"push"},
+		AccessToken: "github:access",
+		SecretToken: "github:secret",
+	}}}, definition.Functions["bar"].Sources)
 }
 
 func TestRandString(t *testing.T) {
@@ -40,6 +51,9 @@ func TestIsLocal(t *testing.T) {
 }
 
 func TestIsRemote(t *testing.T) {
+	if testing.Short() {
+		t.Skip("test requires network access")
+	}
 	testCases := []struct {
 		path   string
 		result bool
@@ -58,6 +72,9 @@ func TestIsRemote(t *testing.T) {
 }
 
 func TestIsGit(t *testing.T) {
+	if testing.Short() {
+		t.Skip("test requires network access")
+	}
 	testCases := []struct {
 		path   string
 		result bool
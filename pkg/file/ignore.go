@@ -0,0 +1,97 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/src-d/go-git.v4/plumbing/format/gitignore"
+)
+
+// IgnoreFiles are the files with the patterns of source paths excluded from
+// the upload. Only the first file found in the source root is used.
+var IgnoreFiles = []string{".tmignore", ".gitignore", ".dockerignore"}
+
+// alwaysIgnored patterns are excluded regardless of ignore files content
+var alwaysIgnored = []string{".git"}
+
+// Walk walks the source directory tree skipping the paths matched by the ignore file patterns.
+// Walk function receives the path relative to the root directory, "." for the root itself.
+func Walk(root string, fn filepath.WalkFunc) error {
+	matcher, err := ignoreMatcher(root)
+	if err != nil {
+		return err
+	}
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		if rel != "." && matcher.Match(strings.Split(filepath.ToSlash(rel), "/"), info.IsDir()) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		return fn(rel, info, nil)
+	})
+}
+
+// ignoreMatcher reads patterns from the first ignore file in the root directory
+func ignoreMatcher(root string) (gitignore.Matcher, error) {
+	var patterns []gitignore.Pattern
+	for _, pattern := range alwaysIgnored {
+		patterns = append(patterns, gitignore.ParsePattern(pattern, nil))
+	}
+	for _, name := range IgnoreFiles {
+		f, err := os.Open(filepath.Join(root, name))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			patterns = append(patterns, gitignore.ParsePattern(line, nil))
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		break
+	}
+	return gitignore.NewMatcher(patterns), nil
+}
+
+// Ignored returns true if the path relative to the source root directory
+// is excluded from the upload by the ignore file patterns
+func Ignored(root, rel string, isDir bool) bool {
+	matcher, err := ignoreMatcher(root)
+	if err != nil {
+		return false
+	}
+	return matcher.Match(strings.Split(filepath.ToSlash(rel), "/"), isDir)
+}
@@ -17,19 +17,39 @@ limitations under the License.
 package file
 
 import (
+	"archive/tar"
 	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"path"
+	"path/filepath"
 	"strings"
+	"time"
 
-	"github.com/mholt/archiver"
 	"github.com/triggermesh/tm/pkg/client"
 	"k8s.io/client-go/tools/remotecommand"
 )
 
+const (
+	// UploadArchive is the path of the uploaded sources archive in the remote container
+	UploadArchive = "/tmp/sources.tar.gz"
+	// UploadChecksum is the path of the uploaded archive checksum file in sha256sum format
+	UploadChecksum = UploadArchive + ".sha256"
+
+	uploadAttempts = 3
+)
+
+var (
+	uploadRetryDelay = 2 * time.Second
+	// remoteExec runs commands in the remote container, replaced in tests
+	remoteExec = (*Copy).RemoteExec
+)
+
 // Copy contains information to copy local path to remote destination
 type Copy struct {
 	Pod         string
@@ -39,30 +59,160 @@ type Copy struct {
 	Destination string
 }
 
-// Upload receives Copy structure, creates tarball of local source path and uploads it to active (un)tar process on remote pod
+// Upload receives Copy structure, creates gzipped tarball of local source path and uploads it to remote pod.
+// Paths matching the patterns from the source ignore file are not uploaded.
+// Upload is retried if the checksum of the received archive doesn't match the local one.
+// After successful upload the checksum file is written next to the archive for the receiver to verify it.
 func (c *Copy) Upload(clientset *client.ConfigSet) error {
 	uploadPath := "/tmp/tm/upload"
 	if err := os.MkdirAll(uploadPath, os.ModePerm); err != nil {
 		return err
 	}
 
-	tar := path.Join(uploadPath, RandString(10)+".tar")
-	if err := archiver.DefaultTar.Archive([]string{c.Source}, tar); err != nil {
+	tarball := path.Join(uploadPath, RandString(10)+".tar.gz")
+	out, err := os.Create(tarball)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tarball)
+	checksum := sha256.New()
+	files, size, err := c.archive(io.MultiWriter(out, checksum))
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("packing sources: %s", err)
+	}
+	sum := hex.EncodeToString(checksum.Sum(nil))
+
+	fileReader, err := os.Open(tarball)
+	if err != nil {
+		return err
+	}
+	defer fileReader.Close()
+	stat, err := fileReader.Stat()
+	if err != nil {
 		return err
 	}
-	clientset.Log.Debugf("sources are packed into %q archive, opening reader\n", tar)
+	clientset.Log.Debugf("%d files (%s) are packed into %q archive (%s), sha256 %s\n", files, formatSize(size), tarball, formatSize(stat.Size()), sum)
 
-	fileReader, err := os.Open(tar)
+	for attempt := 1; ; attempt++ {
+		if err = c.send(clientset, fileReader, stat.Size(), sum); err == nil || attempt == uploadAttempts {
+			break
+		}
+		clientset.Log.Warnf("Upload attempt %d of %d failed: %s, retrying", attempt, uploadAttempts, err)
+		time.Sleep(time.Duration(attempt) * uploadRetryDelay)
+	}
 	if err != nil {
+		return fmt.Errorf("uploading sources: %s", err)
+	}
+
+	clientset.Log.Debugf("writing archive checksum to %q", UploadChecksum)
+	_, _, err = remoteExec(c, clientset, "dd of="+UploadChecksum, strings.NewReader(fmt.Sprintf("%s  %s\n", sum, UploadArchive)))
+	return err
+}
+
+// send streams the archive to the remote pod and verifies the checksum of the received file
+func (c *Copy) send(clientset *client.ConfigSet, archive *os.File, size int64, sum string) error {
+	if _, err := archive.Seek(0, io.SeekStart); err != nil {
 		return err
 	}
+	var reader io.Reader = archive
+	out := progressOutput(clientset)
+	if out != nil {
+		reader = &progress{reader: archive, out: out, prefix: "Uploading", total: size}
+	}
 
-	clientset.Log.Debugf("starting remote untar proccess")
-	command := fmt.Sprintf("tar -xvf - -C /home")
-	stdout, stderr, err := c.RemoteExec(clientset, command, fileReader)
+	clientset.Log.Debugf("starting remote upload process")
+	stdout, stderr, err := remoteExec(c, clientset, "dd of="+UploadArchive, reader)
 	clientset.Log.Debugf("stdout:\n%s", stdout)
 	clientset.Log.Debugf("stderr:\n%s", stderr)
-	return err
+	if err != nil {
+		if out != nil && reader.(*progress).read < size {
+			// finish interrupted progress bar line
+			fmt.Fprintln(out)
+		}
+		return err
+	}
+
+	stdout, _, err = remoteExec(c, clientset, "sha256sum "+UploadArchive, nil)
+	if err != nil {
+		return fmt.Errorf("verifying checksum: %s", err)
+	}
+	if fields := strings.Fields(stdout); len(fields) == 0 || fields[0] != sum {
+		return fmt.Errorf("checksum mismatch, received archive is incomplete")
+	}
+	return nil
+}
+
+// archive writes gzipped tarball of the source directory to w skipping ignored paths
+// and returns the number and the total size of packed files.
+// Archive entries are placed into the directory with the source directory name.
+func (c *Copy) archive(w io.Writer) (int, int64, error) {
+	root, err := filepath.Abs(c.Source)
+	if err != nil {
+		return 0, 0, err
+	}
+	base := filepath.Base(root)
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	var files int
+	var size int64
+	err = Walk(root, func(rel string, info os.FileInfo, err error) error {
+		var link string
+		if info.Mode()&os.ModeSymlink != 0 {
+			if link, err = os.Readlink(filepath.Join(root, rel)); err != nil {
+				return err
+			}
+		}
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		header.Name = path.Join(base, filepath.ToSlash(rel))
+		if info.IsDir() {
+			header.Name += "/"
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		f, err := os.Open(filepath.Join(root, rel))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if _, err := io.Copy(tw, f); err != nil {
+			return err
+		}
+		files++
+		size += info.Size()
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	if err := tw.Close(); err != nil {
+		return 0, 0, err
+	}
+	return files, size, gz.Close()
+}
+
+// formatSize returns human readable size
+func formatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
 }
 
 // RemoteExec executes command on remote pod and returns stdout and stderr output
@@ -79,7 +229,7 @@ func (c *Copy) RemoteExec(clientset *client.ConfigSet, command string, file io.R
 		stdin = "true"
 	}
 	// workaround to form correct URL
-	urlAndParams := strings.Split(clientset.Core.RESTClient().Post().URL().String(), "?")
+	urlAndParams := strings.Split(clientset.Core.Discovery().RESTClient().Post().URL().String(), "?")
 	url := fmt.Sprintf("%sapi/v1/namespaces/%s/pods/%s/exec?stderr=true&stdin=%s&stdout=true%s", urlAndParams[0], c.Namespace, c.Pod, stdin, commandLine)
 	if len(urlAndParams) == 2 {
 		url = fmt.Sprintf("%s&%s", url, urlAndParams[1])
@@ -0,0 +1,189 @@
+package file
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/client/fake"
+)
+
+func TestArchive(t *testing.T) {
+	testCases := []struct {
+		name    string
+		files   map[string]string
+		entries []string
+	}{
+		{
+			name: "no ignore file",
+			files: map[string]string{
+				"main.go":   "package main",
+				".git/HEAD": "ref: refs/heads/master",
+			},
+			entries: []string{"src/", "src/main.go"},
+		}, {
+			name: "tmignore",
+			files: map[string]string{
+				".tmignore":                "# local files\nnode_modules/\n*.log\n!keep.log\n/build\n",
+				".gitignore":               "main.go\n",
+				"main.go":                  "package main",
+				"debug.log":                "debug",
+				"keep.log":                 "keep",
+				"build/out":                "binary",
+				"lib/build/util.go":        "package build",
+				"node_modules/foo/foo.js":  "foo",
+				"lib/node_modules/bar.js":  "bar",
+				".git/objects/pack/foo.gz": "pack",
+			},
+			entries: []string{"src/", "src/.gitignore", "src/.tmignore", "src/keep.log", "src/lib/", "src/lib/build/", "src/lib/build/util.go", "src/main.go"},
+		}, {
+			name: "dockerignore fallback",
+			files: map[string]string{
+				".dockerignore": "venv\n",
+				"handler.py":    "pass",
+				"venv/bin/pip":  "pip",
+			},
+			entries: []string{"src/", "src/.dockerignore", "src/handler.py"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "tm-archive")
+			require.NoError(t, err)
+			defer os.RemoveAll(dir)
+			root := filepath.Join(dir, "src")
+			for name, content := range tc.files {
+				path := filepath.Join(root, name)
+				require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+				require.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+			}
+
+			var buf bytes.Buffer
+			c := Copy{Source: root}
+			_, _, err = c.archive(&buf)
+			require.NoError(t, err)
+
+			gz, err := gzip.NewReader(&buf)
+			require.NoError(t, err)
+			tr := tar.NewReader(gz)
+			var entries []string
+			for {
+				header, err := tr.Next()
+				if err == io.EOF {
+					break
+				}
+				require.NoError(t, err)
+				entries = append(entries, header.Name)
+			}
+			sort.Strings(entries)
+			sort.Strings(tc.entries)
+			assert.Equal(t, tc.entries, entries)
+		})
+	}
+}
+
+func TestProgress(t *testing.T) {
+	var out bytes.Buffer
+	data := bytes.Repeat([]byte("a"), 2048)
+	p := &progress{reader: bytes.NewReader(data), out: &out, prefix: "Uploading", total: int64(len(data))}
+
+	read, err := ioutil.ReadAll(p)
+	require.NoError(t, err)
+	assert.Equal(t, data, read)
+	assert.Equal(t, "\rUploading [==============================] 2.0 KiB / 2.0 KiB 100%\n", out.String()[strings.LastIndex(out.String(), "\r"):])
+	assert.Equal(t, 1, strings.Count(out.String(), "\n"))
+}
+
+func TestFormatSize(t *testing.T) {
+	testCases := map[int64]string{
+		0:               "0 B",
+		1023:            "1023 B",
+		1536:            "1.5 KiB",
+		5 * 1024 * 1024: "5.0 MiB",
+		3 << 30:         "3.0 GiB",
+	}
+	for size, result := range testCases {
+		assert.Equal(t, result, formatSize(size))
+	}
+}
+
+func TestUploadRetry(t *testing.T) {
+	testCases := []struct {
+		name string
+		// corrupted is the number of uploads that are received incomplete
+		corrupted int
+		uploads   int
+		wantErr   bool
+	}{
+		{name: "first attempt", corrupted: 0, uploads: 1},
+		{name: "retry after checksum mismatch", corrupted: 1, uploads: 2},
+		{name: "all attempts fail", corrupted: uploadAttempts, uploads: uploadAttempts, wantErr: true},
+	}
+
+	dir, err := ioutil.TempDir("", "tm-upload")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "main.go"), []byte("package main"), 0644))
+
+	delay := uploadRetryDelay
+	uploadRetryDelay = 0
+	defer func() {
+		uploadRetryDelay = delay
+		remoteExec = (*Copy).RemoteExec
+	}()
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var uploads int
+			var received []byte
+			var checksumFile string
+			remoteExec = func(c *Copy, clientset *client.ConfigSet, command string, file io.Reader) (string, string, error) {
+				switch command {
+				case "dd of=" + UploadArchive:
+					uploads++
+					data, err := ioutil.ReadAll(file)
+					if err != nil {
+						return "", "", err
+					}
+					if uploads <= tc.corrupted {
+						data = data[:len(data)/2]
+					}
+					received = data
+				case "sha256sum " + UploadArchive:
+					return fmt.Sprintf("%x  %s\n", sha256.Sum256(received), UploadArchive), "", nil
+				case "dd of=" + UploadChecksum:
+					data, err := ioutil.ReadAll(file)
+					checksumFile = string(data)
+					return "", "", err
+				default:
+					return "", "", fmt.Errorf("unexpected command %q", command)
+				}
+				return "", "", nil
+			}
+
+			c := &Copy{Pod: "foo", Namespace: fake.Namespace, Source: dir}
+			err := c.Upload(fake.NewConfigSet())
+			assert.Equal(t, tc.uploads, uploads)
+			if tc.wantErr {
+				assert.Error(t, err)
+				assert.Empty(t, checksumFile, "checksum must not be written for incomplete archive")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, fmt.Sprintf("%x  %s\n", sha256.Sum256(received), UploadArchive), checksumFile)
+		})
+	}
+}
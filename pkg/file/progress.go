@@ -0,0 +1,78 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/triggermesh/tm/pkg/client"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+const (
+	progressBarWidth = 30
+	progressInterval = 200 * time.Millisecond
+)
+
+// progress is a reader that writes the bar with the number of read bytes to the output
+type progress struct {
+	reader  io.Reader
+	out     io.Writer
+	prefix  string
+	total   int64
+	read    int64
+	printed time.Time
+}
+
+// progressOutput returns the output for progress bar or nil if logger doesn't write to the terminal
+func progressOutput(clientset *client.ConfigSet) io.Writer {
+	out, ok := clientset.Log.Out.(*os.File)
+	if !ok || !terminal.IsTerminal(int(out.Fd())) {
+		return nil
+	}
+	return out
+}
+
+func (p *progress) Read(b []byte) (int, error) {
+	n, err := p.reader.Read(b)
+	p.read += int64(n)
+	if n > 0 && (p.read == p.total || time.Since(p.printed) >= progressInterval) {
+		p.print()
+		p.printed = time.Now()
+	}
+	return n, err
+}
+
+func (p *progress) print() {
+	percent := int64(100)
+	if p.total > 0 {
+		percent = 100 * p.read / p.total
+	}
+	filled := int(percent) * progressBarWidth / 100
+	fmt.Fprintf(p.out, "\r%s [%s%s] %s / %s %3d%%",
+		p.prefix,
+		strings.Repeat("=", filled),
+		strings.Repeat(" ", progressBarWidth-filled),
+		formatSize(p.read),
+		formatSize(p.total),
+		percent)
+	if p.read >= p.total {
+		fmt.Fprintln(p.out)
+	}
+}
@@ -0,0 +1,267 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// variablePattern matches values that are resolved while parsing the manifest
+const variablePattern = `\$\{.+\}`
+
+// fieldRule contains manifest field constraints that can't be expressed by the field type
+type fieldRule struct {
+	path   string
+	schema map[string]interface{}
+	check  func(value string) error
+}
+
+// fieldRules are matched against the field path where "*" matches any key
+// and "[]" suffix denotes array items. Stage overrides share the rules of
+// provider and functions.
+var fieldRules = []fieldRule{
+	enumRule("provider.name", "triggermesh"),
+	enumRule("provider.pull-policy", "Always", "IfNotPresent", "Never"),
+	durationRule("provider.buildtimeout"),
+	quantityRule("provider.resources.*.*"),
+	rangeRule("provider.scaling.*", 0, -1),
+	rangeRule("functions.*.concurrency", 0, -1),
+	keyValueRule("functions.*.buildargs[]"),
+	keyValueRule("functions.*.labels[]"),
+	cronRule("functions.*.schedule[].cron"),
+	rangeRule("functions.*.traffic[].percent", 0, 100),
+	rangeRule("functions.*.canary.steps[]", 1, 100),
+	durationRule("functions.*.canary.interval"),
+	quantityRule("functions.*.resources.*.*"),
+	rangeRule("functions.*.scaling.*", 0, -1),
+	enumRule("functions.*.sources[].apiserver.mode", "Reference", "Resource"),
+}
+
+func enumRule(path string, values ...string) fieldRule {
+	return fieldRule{
+		path:   path,
+		schema: map[string]interface{}{"enum": values},
+		check: func(value string) error {
+			for _, v := range values {
+				if v == value {
+					return nil
+				}
+			}
+			return fmt.Errorf("%q is not one of %s", value, strings.Join(values, ", "))
+		},
+	}
+}
+
+func durationRule(path string) fieldRule {
+	return fieldRule{
+		path:   path,
+		schema: map[string]interface{}{"pattern": `^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`},
+		check: func(value string) error {
+			_, err := time.ParseDuration(value)
+			return err
+		},
+	}
+}
+
+func quantityRule(path string) fieldRule {
+	return fieldRule{
+		path:   path,
+		schema: map[string]interface{}{"pattern": `^[+-]?[0-9.]+([eEinumkKMGTP]*[-+]?[0-9]*)$`},
+		check: func(value string) error {
+			_, err := resource.ParseQuantity(value)
+			if err != nil {
+				return fmt.Errorf("%q is not a valid quantity", value)
+			}
+			return nil
+		},
+	}
+}
+
+// rangeRule limits integer field values, negative max means no upper limit
+func rangeRule(path string, min, max int) fieldRule {
+	schema := map[string]interface{}{"minimum": min}
+	if max >= 0 {
+		schema["maximum"] = max
+	}
+	return fieldRule{
+		path:   path,
+		schema: schema,
+		check: func(value string) error {
+			v, err := strconv.Atoi(value)
+			if err != nil {
+				return err
+			}
+			if v < min || (max >= 0 && v > max) {
+				if max < 0 {
+					return fmt.Errorf("value %d must not be less than %d", v, min)
+				}
+				return fmt.Errorf("value %d is out of %d-%d range", v, min, max)
+			}
+			return nil
+		},
+	}
+}
+
+var keyValue = regexp.MustCompile("[:=]")
+
+func keyValueRule(path string) fieldRule {
+	return fieldRule{
+		path:   path,
+		schema: map[string]interface{}{"pattern": `^[^:=]+[:=]`},
+		check: func(value string) error {
+			if t := keyValue.Split(value, 2); len(t) != 2 || t[0] == "" {
+				return fmt.Errorf("%q is not in key=value format", value)
+			}
+			return nil
+		},
+	}
+}
+
+func cronRule(path string) fieldRule {
+	return fieldRule{
+		path:   path,
+		schema: map[string]interface{}{"description": "Standard cron expression, e.g. \"*/5 * * * *\""},
+		check: func(value string) error {
+			_, err := cron.ParseStandard(value)
+			return err
+		},
+	}
+}
+
+// ruleFor returns the rule matching field path
+func ruleFor(path []string) *fieldRule {
+	if len(path) > 2 && path[0] == "stages" {
+		path = path[2:]
+	}
+	for i, rule := range fieldRules {
+		pattern := strings.Split(rule.path, ".")
+		if len(pattern) != len(path) {
+			continue
+		}
+		matches := true
+		for j, segment := range pattern {
+			if !matchSegment(segment, path[j]) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return &fieldRules[i]
+		}
+	}
+	return nil
+}
+
+var itemIndex = regexp.MustCompile(`\[[0-9]+\]$`)
+
+func matchSegment(pattern, segment string) bool {
+	segment = itemIndex.ReplaceAllString(segment, "[]")
+	if strings.HasPrefix(pattern, "*") {
+		return strings.HasSuffix(pattern, "[]") == strings.HasSuffix(segment, "[]")
+	}
+	return pattern == segment
+}
+
+// yamlFieldName returns the manifest key of the struct field
+func yamlFieldName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+	if name == "" {
+		name = strings.ToLower(field.Name)
+	}
+	return name
+}
+
+// JSONSchema returns JSON Schema of serverless.yaml manifest
+func JSONSchema() ([]byte, error) {
+	schema := typeSchema(reflect.TypeOf(Definition{}), nil)
+	schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+	schema["title"] = "TriggerMesh serverless.yaml"
+	schema["required"] = []string{"service"}
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+func typeSchema(t reflect.Type, path []string) map[string]interface{} {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return typeSchema(t.Elem(), path)
+	case reflect.Struct:
+		properties := make(map[string]interface{})
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := yamlFieldName(field)
+			if name == "-" {
+				continue
+			}
+			properties[name] = typeSchema(field.Type, append(path, name))
+		}
+		return map[string]interface{}{
+			"type":                 "object",
+			"properties":           properties,
+			"additionalProperties": false,
+		}
+	case reflect.Map:
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": typeSchema(t.Elem(), append(path, "*")),
+		}
+	case reflect.Slice:
+		items := append([]string{}, path...)
+		items[len(items)-1] += "[]"
+		return map[string]interface{}{
+			"type":  "array",
+			"items": typeSchema(t.Elem(), items),
+		}
+	}
+
+	schema := map[string]interface{}{"type": scalarType(t)}
+	rule := ruleFor(path)
+	if rule != nil {
+		for k, v := range rule.schema {
+			schema[k] = v
+		}
+	}
+	if rule == nil && t.Kind() == reflect.String {
+		return schema
+	}
+	// variables are resolved before type and rule checks
+	return map[string]interface{}{
+		"anyOf": []interface{}{
+			schema,
+			map[string]interface{}{"type": "string", "pattern": variablePattern},
+		},
+	}
+}
+
+func scalarType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	}
+	return "string"
+}
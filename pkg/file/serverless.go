@@ -17,6 +17,14 @@ type Definition struct {
 	Repository  string              `yaml:"repository,omitempty"`
 	Functions   map[string]Function `yaml:"functions,omitempty"`
 	Include     []string            `yaml:"include,omitempty"`
+	Stages      map[string]Stage    `yaml:"stages,omitempty"`
+}
+
+// Stage contains provider and functions parameters that override
+// the manifest values when the stage is selected
+type Stage struct {
+	Provider  TriggermeshProvider `yaml:"provider,omitempty"`
+	Functions map[string]Function `yaml:"functions,omitempty"`
 }
 
 // TriggermeshProvider structure contains serverless provider parameters specific to triggermesh
@@ -29,6 +37,12 @@ type TriggermeshProvider struct {
 	Environment  map[string]string `yaml:"environment,omitempty"`
 	EnvSecrets   []string          `yaml:"env-secrets,omitempty"`
 	Annotations  map[string]string `yaml:"annotations,omitempty"`
+	Resources    Resources         `yaml:"resources,omitempty"`
+	Scaling      Scaling           `yaml:"scaling,omitempty"`
+	// ServiceAccount is used by the functions pods and image builds
+	ServiceAccount string `yaml:"serviceAccount,omitempty"`
+	// ServiceAccountAlias is the hyphenated spelling of ServiceAccount
+	ServiceAccountAlias string `yaml:"service-account,omitempty"`
 
 	// registry configs moved to client Configset
 	// these variables kept for backward compatibility
@@ -38,18 +52,92 @@ type TriggermeshProvider struct {
 
 // Function describes function definition in serverless format
 type Function struct {
-	Handler     string            `yaml:"handler,omitempty"`
-	Source      string            `yaml:"source,omitempty"`
-	Revision    string            `yaml:"revision,omitempty"`
-	Runtime     string            `yaml:"runtime,omitempty"`
-	Concurrency int               `yaml:"concurrency,omitempty"`
-	Buildargs   []string          `yaml:"buildargs,omitempty"`
-	Description string            `yaml:"description,omitempty"`
-	Labels      []string          `yaml:"labels,omitempty"`
+	Handler       string            `yaml:"handler,omitempty"`
+	Source        string            `yaml:"source,omitempty"`
+	Revision      string            `yaml:"revision,omitempty"`
+	Runtime       string            `yaml:"runtime,omitempty"`
+	Concurrency   int               `yaml:"concurrency,omitempty"`
+	Buildargs     []string          `yaml:"buildargs,omitempty"`
+	Description   string            `yaml:"description,omitempty"`
+	Labels        []string          `yaml:"labels,omitempty"`
+	Environment   map[string]string `yaml:"environment,omitempty"`
+	EnvSecrets    []string          `yaml:"env-secrets,omitempty"`
+	Annotations   map[string]string `yaml:"annotations,omitempty"`
+	Schedule      []Schedule        `yaml:"schedule,omitempty"`
+	Traffic       []Traffic         `yaml:"traffic,omitempty"`
+	Canary        Canary            `yaml:"canary,omitempty"`
+	Resources     Resources         `yaml:"resources,omitempty"`
+	Scaling       Scaling           `yaml:"scaling,omitempty"`
+	Events        []Event           `yaml:"events,omitempty"`
+	Subscriptions []Subscription    `yaml:"subscriptions,omitempty"`
+	Sources       []Source          `yaml:"sources,omitempty"`
+	// ServiceAccount overrides the provider service account
+	ServiceAccount string `yaml:"serviceAccount,omitempty"`
+	// ServiceAccountAlias is the hyphenated spelling of ServiceAccount
+	ServiceAccountAlias string `yaml:"service-account,omitempty"`
+}
+
+// Event describes CloudEvents that function subscribes to through the broker.
+// Type is a shortcut for the "type" attribute filter, Broker defaults to "default".
+type Event struct {
+	Broker string            `yaml:"broker,omitempty"`
+	Type   string            `yaml:"type,omitempty"`
+	Filter map[string]string `yaml:"filter,omitempty"`
+}
+
+// Subscription describes channel that function receives events from.
+// If Reply channel is set, function responses are sent there.
+type Subscription struct {
+	Channel string `yaml:"channel,omitempty"`
+	Reply   string `yaml:"reply,omitempty"`
+}
+
+// Source describes event source that sends events to the function.
+// Exactly one of the source kinds must be set.
+type Source struct {
+	APIServer   *APIServerSource   `yaml:"apiserver,omitempty"`
+	SinkBinding *SinkBindingSource `yaml:"sinkbinding,omitempty"`
+	Container   *ContainerSource   `yaml:"container,omitempty"`
+	GitHub      *GitHubSource      `yaml:"github,omitempty"`
+}
+
+// APIServerSource sends k8s API events about the resources of the given kinds.
+// Mode is either "Reference" (default) or "Resource".
+type APIServerSource struct {
+	Resources      []APIResource `yaml:"resources,omitempty"`
+	Mode           string        `yaml:"mode,omitempty"`
+	ServiceAccount string        `yaml:"service-account,omitempty"`
+}
+
+// APIResource selects k8s resources by kind and labels.
+// Name can be used instead of labels in sinkbinding subject only.
+type APIResource struct {
+	APIVersion string            `yaml:"api-version,omitempty"`
+	Kind       string            `yaml:"kind,omitempty"`
+	Name       string            `yaml:"name,omitempty"`
+	Labels     map[string]string `yaml:"labels,omitempty"`
+}
+
+// SinkBindingSource injects function URL into the subject resources
+type SinkBindingSource struct {
+	Subject APIResource `yaml:"subject,omitempty"`
+}
+
+// ContainerSource runs the image that sends events to the function
+type ContainerSource struct {
+	Image       string            `yaml:"image,omitempty"`
+	Args        []string          `yaml:"args,omitempty"`
 	Environment map[string]string `yaml:"environment,omitempty"`
-	EnvSecrets  []string          `yaml:"env-secrets,omitempty"`
-	Annotations map[string]string `yaml:"annotations,omitempty"`
-	Schedule    []Schedule        `yaml:"schedule,omitempty"`
+}
+
+// GitHubSource sends GitHub repository webhook events.
+// Tokens are the references to the secret keys in "secret-name:key" format.
+type GitHubSource struct {
+	Repository  string   `yaml:"repository,omitempty"`
+	EventTypes  []string `yaml:"event-types,omitempty"`
+	AccessToken string   `yaml:"access-token,omitempty"`
+	SecretToken string   `yaml:"secret-token,omitempty"`
+	APIURL      string   `yaml:"api-url,omitempty"`
 }
 
 // Schedule struct contains a data in JSON format and a cron
@@ -61,11 +149,48 @@ type Schedule struct {
 	Description string
 }
 
+// Traffic describes the share of requests routed to a service revision.
+// "@latest" revision name refers to the latest ready revision, Tag
+// creates dedicated revision URL.
+type Traffic struct {
+	Revision string `yaml:"revision,omitempty"`
+	Tag      string `yaml:"tag,omitempty"`
+	Percent  int    `yaml:"percent,omitempty"`
+}
+
+// Canary contains a sequence of traffic percents that should be shifted
+// to the latest revision with the given interval between the steps
+type Canary struct {
+	Steps    []int  `yaml:"steps,omitempty"`
+	Interval string `yaml:"interval,omitempty"`
+}
+
+// Resources contains container compute resources requests and limits,
+// e.g. "cpu: 100m" or "memory: 128Mi"
+type Resources struct {
+	Requests map[string]string `yaml:"requests,omitempty"`
+	Limits   map[string]string `yaml:"limits,omitempty"`
+}
+
+// Scaling contains knative autoscaler parameters of the function revisions
+type Scaling struct {
+	MinScale          int `yaml:"min-scale,omitempty"`
+	MaxScale          int `yaml:"max-scale,omitempty"`
+	Target            int `yaml:"target,omitempty"`
+	TargetUtilization int `yaml:"target-utilization,omitempty"`
+}
+
 // Aos returns filesystem object with standard set of os methods implemented by afero package
 var Aos = afero.NewOsFs()
 
 // ParseManifest accepts serverless yaml file path and returns decoded structure
 func ParseManifest(path string) (Definition, error) {
+	return ParseStageManifest(path, "")
+}
+
+// ParseStageManifest reads serverless yaml file, applies selected stage overrides,
+// resolves variables and returns decoded structure
+func ParseStageManifest(path, stage string) (Definition, error) {
 	var definition Definition
 
 	exists, err := afero.Exists(Aos, path)
@@ -80,10 +205,47 @@ func ParseManifest(path string) (Definition, error) {
 		return definition, err
 	}
 
+	root := make(map[interface{}]interface{})
+	if err = yaml.Unmarshal(data, &root); err != nil {
+		return definition, err
+	}
+	if err = applyStage(root, stage); err != nil {
+		return definition, err
+	}
+	r := resolver{
+		root:    root,
+		workdir: filepath.Dir(path),
+		stage:   stage,
+	}
+	resolved, err := r.interpolate(root, 0)
+	if err != nil {
+		return definition, err
+	}
+	if data, err = yaml.Marshal(resolved); err != nil {
+		return definition, err
+	}
+
 	definition.Repository = filepath.Base(filepath.Dir(path))
-	err = yaml.UnmarshalStrict(data, &definition)
+	if err = yaml.UnmarshalStrict(data, &definition); err != nil {
+		return definition, err
+	}
+	definition.resolveAliases()
+	return definition, nil
+}
 
-	return definition, err
+// resolveAliases moves values of the alternative key spellings to the main fields
+func (definition *Definition) resolveAliases() {
+	if definition.Provider.ServiceAccount == "" {
+		definition.Provider.ServiceAccount = definition.Provider.ServiceAccountAlias
+	}
+	definition.Provider.ServiceAccountAlias = ""
+	for name, function := range definition.Functions {
+		if function.ServiceAccount == "" {
+			function.ServiceAccount = function.ServiceAccountAlias
+		}
+		function.ServiceAccountAlias = ""
+		definition.Functions[name] = function
+	}
 }
 
 // Validate function verifies that provided service Definition object contains required set of keys and values
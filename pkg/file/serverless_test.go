@@ -2,6 +2,7 @@ package file
 
 import (
 	"io/ioutil"
+	"os"
 	"testing"
 
 	"github.com/spf13/afero"
@@ -36,3 +37,153 @@ func TestParseManifestInvalid(t *testing.T) {
 	assert.Contains(t, err.Error(), "yaml: unmarshal errors")
 	assert.Empty(t, definition.Service)
 }
+
+const serviceAccountManifest = `service: foo
+provider:
+  serviceAccount: runtime
+functions:
+  bar:
+    source: gcr.io/foo/bar
+    serviceAccount: bar-runtime
+  baz:
+    source: gcr.io/foo/baz
+    service-account: baz-runtime
+  qux:
+    source: gcr.io/foo/qux
+`
+
+func TestParseManifestServiceAccount(t *testing.T) {
+	Aos = afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(Aos, "serverless.yaml", []byte(serviceAccountManifest), 664))
+
+	definition, err := ParseManifest("serverless.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "runtime", definition.Provider.ServiceAccount)
+	assert.Equal(t, "bar-runtime", definition.Functions["bar"].ServiceAccount)
+	assert.Equal(t, "baz-runtime", definition.Functions["baz"].ServiceAccount)
+	assert.Empty(t, definition.Functions["baz"].ServiceAccountAlias)
+	assert.Empty(t, definition.Functions["qux"].ServiceAccount)
+}
+
+const stagedManifest = `service: foo
+provider:
+  namespace: ${env:TM_TEST_NAMESPACE, default}
+  environment:
+    STAGE: ${opt:stage, dev}
+    DB_HOST: ${file(./config.yaml):${opt:stage, dev}.db}
+functions:
+  bar:
+    source: gcr.io/foo/bar:${opt:stage, latest}
+    environment:
+      NAMESPACE: ${self:provider.namespace}
+    scaling:
+      max-scale: ${file(config.yaml):scale}
+stages:
+  prod:
+    provider:
+      namespace: production
+    functions:
+      bar:
+        scaling:
+          min-scale: 2
+`
+
+const stagedConfig = `scale: 5
+dev:
+  db: localhost
+prod:
+  db: db.prod.svc
+`
+
+func TestParseStageManifest(t *testing.T) {
+	Aos = afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(Aos, "foo/serverless.yaml", []byte(stagedManifest), 664))
+	require.NoError(t, afero.WriteFile(Aos, "foo/config.yaml", []byte(stagedConfig), 664))
+
+	definition, err := ParseStageManifest("foo/serverless.yaml", "")
+	require.NoError(t, err)
+	assert.Equal(t, "default", definition.Provider.Namespace)
+	assert.Equal(t, "dev", definition.Provider.Environment["STAGE"])
+	assert.Equal(t, "localhost", definition.Provider.Environment["DB_HOST"])
+	assert.Equal(t, "gcr.io/foo/bar:latest", definition.Functions["bar"].Source)
+	assert.Equal(t, "default", definition.Functions["bar"].Environment["NAMESPACE"])
+	assert.Equal(t, 5, definition.Functions["bar"].Scaling.MaxScale)
+	assert.Equal(t, 0, definition.Functions["bar"].Scaling.MinScale)
+
+	definition, err = ParseStageManifest("foo/serverless.yaml", "prod")
+	require.NoError(t, err)
+	assert.Equal(t, "production", definition.Provider.Namespace)
+	assert.Equal(t, "prod", definition.Provider.Environment["STAGE"])
+	assert.Equal(t, "db.prod.svc", definition.Provider.Environment["DB_HOST"])
+	assert.Equal(t, "gcr.io/foo/bar:prod", definition.Functions["bar"].Source)
+	assert.Equal(t, "production", definition.Functions["bar"].Environment["NAMESPACE"])
+	assert.Equal(t, 5, definition.Functions["bar"].Scaling.MaxScale)
+	assert.Equal(t, 2, definition.Functions["bar"].Scaling.MinScale)
+
+	os.Setenv("TM_TEST_NAMESPACE", "from-env")
+	defer os.Unsetenv("TM_TEST_NAMESPACE")
+	definition, err = ParseStageManifest("foo/serverless.yaml", "")
+	require.NoError(t, err)
+	assert.Equal(t, "from-env", definition.Provider.Namespace)
+	assert.Equal(t, "from-env", definition.Functions["bar"].Environment["NAMESPACE"])
+
+	_, err = ParseStageManifest("foo/serverless.yaml", "staging")
+	assert.EqualError(t, err, `stage "staging" is not defined in manifest`)
+}
+
+const literalsManifest = `service: foo
+provider:
+  name: triggermesh
+functions:
+  foo.bar:
+    source: gcr.io/foo/bar
+    environment:
+      SCRIPT: echo ${HOME} $$HOME
+      TEMPLATE: https://example.com/${path}
+      ESCAPED: $${env:HOME}
+      ESCAPED_INNER: $${self:${self:provider.name}}
+  baz:
+    source: ${self:functions."foo.bar".source}
+`
+
+func TestParseManifestLiterals(t *testing.T) {
+	Aos = afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(Aos, "serverless.yaml", []byte(literalsManifest), 664))
+
+	definition, err := ParseStageManifest("serverless.yaml", "")
+	require.NoError(t, err)
+	env := definition.Functions["foo.bar"].Environment
+	assert.Equal(t, "echo ${HOME} $$HOME", env["SCRIPT"])
+	assert.Equal(t, "https://example.com/${path}", env["TEMPLATE"])
+	assert.Equal(t, "${env:HOME}", env["ESCAPED"])
+	assert.Equal(t, "${self:triggermesh}", env["ESCAPED_INNER"])
+	assert.Equal(t, "gcr.io/foo/bar", definition.Functions["baz"].Source)
+}
+
+func TestSplitPath(t *testing.T) {
+	assert.Equal(t, []string{"functions", "foo.bar", "source"}, splitPath(`functions."foo.bar".source`))
+	assert.Equal(t, []string{"functions", "foo.bar"}, splitPath(`functions.'foo.bar'`))
+	assert.Equal(t, []string{"provider"}, splitPath("provider"))
+}
+
+func TestParseManifestVariableErrors(t *testing.T) {
+	testCases := map[string]struct {
+		manifest string
+		stage    string
+	}{
+		"unset variable":    {manifest: "service: ${env:TM_TEST_UNSET_VARIABLE}"},
+		"unknown option":    {manifest: "service: ${opt:region}"},
+		"missing file":      {manifest: "service: ${file(missing.yaml):foo}"},
+		"reference loop":    {manifest: "service: ${self:provider.name}\nprovider:\n  name: ${self:service}"},
+		"map in string":     {manifest: "service: foo-${self:provider}\nprovider:\n  name: triggermesh"},
+		"unsupported stage": {manifest: "service: foo\nstages:\n  dev:\n    service: bar", stage: "dev"},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			Aos = afero.NewMemMapFs()
+			require.NoError(t, afero.WriteFile(Aos, "serverless.yaml", []byte(tc.manifest), 664))
+			_, err := ParseStageManifest("serverless.yaml", tc.stage)
+			assert.Error(t, err)
+		})
+	}
+}
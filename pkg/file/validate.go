@@ -0,0 +1,219 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/afero"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// Problem describes manifest error and its position
+type Problem struct {
+	File    string
+	Line    int
+	Column  int
+	Message string
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s", p.File, p.Line, p.Column, p.Message)
+}
+
+// Include is the manifest include reference with its position
+type Include struct {
+	Path   string
+	Line   int
+	Column int
+}
+
+var (
+	variableValue = regexp.MustCompile(variablePattern)
+	syntaxLine    = regexp.MustCompile(`line ([0-9]+)`)
+)
+
+type validator struct {
+	file     string
+	problems []Problem
+}
+
+// ValidateManifest checks manifest file against definition schema and returns
+// the list of problems and the list of included manifests. Included manifests
+// contain functions only, so service name is not required for them.
+func ValidateManifest(path string, included bool) ([]Problem, []Include, error) {
+	data, err := afero.ReadFile(Aos, path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	v := validator{file: path}
+	var root yamlv3.Node
+	if err := yamlv3.Unmarshal(data, &root); err != nil {
+		line := 0
+		if match := syntaxLine.FindStringSubmatch(err.Error()); match != nil {
+			line, _ = strconv.Atoi(match[1])
+		}
+		v.report(line, 0, strings.TrimPrefix(err.Error(), "yaml: "))
+		return v.problems, nil, nil
+	}
+	if len(root.Content) == 0 {
+		v.report(1, 1, "manifest is empty")
+		return v.problems, nil, nil
+	}
+	document := root.Content[0]
+	v.walk(document, reflect.TypeOf(Definition{}), nil)
+
+	if !included {
+		if service := mappingValue(document, "service"); service == nil || service.Value == "" {
+			v.report(document.Line, document.Column, "service name can't be empty")
+		}
+	}
+
+	var includes []Include
+	if include := mappingValue(document, "include"); include != nil {
+		for _, item := range include.Content {
+			if item.Kind == yamlv3.ScalarNode && !variableValue.MatchString(item.Value) {
+				includes = append(includes, Include{
+					Path:   item.Value,
+					Line:   item.Line,
+					Column: item.Column,
+				})
+			}
+		}
+	}
+	return v.problems, includes, nil
+}
+
+func (v *validator) report(line, column int, format string, args ...interface{}) {
+	v.problems = append(v.problems, Problem{
+		File:    v.file,
+		Line:    line,
+		Column:  column,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *validator) reportNode(node *yamlv3.Node, path []string, format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	if len(path) != 0 {
+		message = strings.Join(path, ".") + ": " + message
+	}
+	v.report(node.Line, node.Column, "%s", message)
+}
+
+// walk compares YAML node with the type of the definition field
+func (v *validator) walk(node *yamlv3.Node, t reflect.Type, path []string) {
+	if node.Kind == yamlv3.AliasNode {
+		node = node.Alias
+	}
+	if node.Kind == yamlv3.ScalarNode && (node.Tag == "!!null" || variableValue.MatchString(node.Value)) {
+		// variable type is known after resolution only
+		return
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yamlv3.MappingNode {
+			v.reportNode(node, path, "must be an object")
+			return
+		}
+		fields := make(map[string]reflect.Type, t.NumField())
+		for i := 0; i < t.NumField(); i++ {
+			fields[yamlFieldName(t.Field(i))] = t.Field(i).Type
+		}
+		v.walkMapping(node, path, func(key string) (reflect.Type, bool) {
+			field, ok := fields[key]
+			return field, ok
+		})
+	case reflect.Map:
+		if node.Kind != yamlv3.MappingNode {
+			v.reportNode(node, path, "must be an object")
+			return
+		}
+		v.walkMapping(node, path, func(string) (reflect.Type, bool) {
+			return t.Elem(), true
+		})
+	case reflect.Slice:
+		if node.Kind != yamlv3.SequenceNode {
+			v.reportNode(node, path, "must be a list")
+			return
+		}
+		for i, item := range node.Content {
+			itemPath := append([]string{}, path...)
+			itemPath[len(itemPath)-1] += fmt.Sprintf("[%d]", i)
+			v.walk(item, t.Elem(), itemPath)
+		}
+	default:
+		v.checkScalar(node, t, path)
+	}
+}
+
+func (v *validator) walkMapping(node *yamlv3.Node, path []string, field func(key string) (reflect.Type, bool)) {
+	seen := make(map[string]bool, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if seen[key.Value] {
+			v.reportNode(key, path, "duplicate key %q", key.Value)
+			continue
+		}
+		seen[key.Value] = true
+		t, ok := field(key.Value)
+		if !ok {
+			v.reportNode(key, path, "unknown field %q", key.Value)
+			continue
+		}
+		v.walk(value, t, append(append([]string{}, path...), key.Value))
+	}
+}
+
+func (v *validator) checkScalar(node *yamlv3.Node, t reflect.Type, path []string) {
+	if node.Kind != yamlv3.ScalarNode {
+		v.reportNode(node, path, "must be a %s", scalarType(t))
+		return
+	}
+	value := reflect.New(t)
+	if t.Kind() != reflect.String {
+		if err := node.Decode(value.Interface()); err != nil {
+			v.reportNode(node, path, "%q is not a valid %s", node.Value, scalarType(t))
+			return
+		}
+	}
+	if rule := ruleFor(path); rule != nil {
+		if err := rule.check(node.Value); err != nil {
+			v.reportNode(node, path, "%s", err)
+		}
+	}
+}
+
+// mappingValue returns the value of the key in YAML mapping node
+func mappingValue(node *yamlv3.Node, key string) *yamlv3.Node {
+	if node.Kind != yamlv3.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
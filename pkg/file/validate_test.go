@@ -0,0 +1,96 @@
+package file
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const invalidManifest = `service: foo
+provider:
+  buildtimeout: 10 minutes
+  pull-policy: Sometimes
+functions:
+  bar:
+    concurrency: -1
+    buildargs:
+      - FOO
+    schedule:
+      - cron: "*/5 * * *"
+    scaling:
+      max-scale: many
+    unknown: true
+    environment:
+      STAGE: ${opt:stage}
+    traffic:
+      - percent: ${env:PERCENT}
+stages:
+  prod:
+    provider:
+      pull-policy: Sometimes
+include:
+  - foo.yaml
+`
+
+func TestValidateManifest(t *testing.T) {
+	Aos = afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(Aos, "serverless.yaml", []byte(invalidManifest), 664))
+
+	problems, includes, err := ValidateManifest("serverless.yaml", false)
+	require.NoError(t, err)
+
+	var positions []int
+	for _, problem := range problems {
+		assert.Equal(t, "serverless.yaml", problem.File)
+		positions = append(positions, problem.Line)
+	}
+	assert.Equal(t, []int{3, 4, 7, 9, 11, 13, 14, 22}, positions)
+	assert.Equal(t, "serverless.yaml:14:5: functions.bar: unknown field \"unknown\"", problems[6].String())
+	assert.Equal(t, []Include{{Path: "foo.yaml", Line: 24, Column: 5}}, includes)
+}
+
+func TestValidateManifestService(t *testing.T) {
+	Aos = afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(Aos, "foo.yaml", []byte("functions:\n  foo:\n    source: foo\n"), 664))
+
+	problems, _, err := ValidateManifest("foo.yaml", true)
+	require.NoError(t, err)
+	assert.Empty(t, problems)
+
+	problems, _, err = ValidateManifest("foo.yaml", false)
+	require.NoError(t, err)
+	require.Len(t, problems, 1)
+	assert.Equal(t, "service name can't be empty", problems[0].Message)
+}
+
+func TestValidateManifestSyntax(t *testing.T) {
+	Aos = afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(Aos, "serverless.yaml", []byte("service: foo\nfunctions:\n  - foo: [\n"), 664))
+
+	problems, _, err := ValidateManifest("serverless.yaml", false)
+	require.NoError(t, err)
+	require.Len(t, problems, 1)
+	assert.NotZero(t, problems[0].Line)
+}
+
+func TestValidateManifestFixture(t *testing.T) {
+	fixture, err := ioutil.ReadFile("../../testfiles/serverless-test.yaml")
+	require.NoError(t, err)
+	Aos = afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(Aos, "serverless.yaml", fixture, 664))
+
+	problems, _, err := ValidateManifest("serverless.yaml", false)
+	require.NoError(t, err)
+	assert.Empty(t, problems)
+}
+
+func TestJSONSchemaIsUpToDate(t *testing.T) {
+	published, err := ioutil.ReadFile("../../schema/serverless.json")
+	require.NoError(t, err)
+	schema, err := JSONSchema()
+	require.NoError(t, err)
+	assert.JSONEq(t, string(schema), string(published), "run \"tm validate --schema > schema/serverless.json\" to update the schema")
+}
@@ -0,0 +1,293 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v2"
+)
+
+// maximum number of nested variable resolutions, protects from reference loops
+const maxResolveDepth = 10
+
+// variable matches innermost ${...} expression with one of the known sources
+// so that nested variables are resolved from inside out. Other ${...} strings,
+// e.g. shell variables, are left as is. Leading $ escapes the expression.
+var (
+	variable     = regexp.MustCompile(`(\$?)\$\{((?:env:|opt:|self:|file\()[^${}]*)\}`)
+	fileVariable = regexp.MustCompile(`^file\(([^()]+)\)(:(.+))?$`)
+)
+
+// resolver substitutes manifest variables:
+// ${env:VAR} - environment variable
+// ${opt:stage} - stage name passed to the CLI
+// ${self:path.to.key} - value from the same manifest
+// ${file(./path.yaml):path.to.key} - value from another YAML file
+// Any variable may have a default value: ${env:VAR, default}
+// Escaped $${...} expressions are replaced with literal ${...}
+type resolver struct {
+	root    map[interface{}]interface{}
+	workdir string
+	stage   string
+}
+
+// interpolate walks through the manifest tree and replaces variables in string values
+func (r *resolver) interpolate(value interface{}, depth int) (interface{}, error) {
+	if depth > maxResolveDepth {
+		return nil, fmt.Errorf("variables nesting is too deep, check for reference loops")
+	}
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		result := make(map[interface{}]interface{}, len(v))
+		for key, field := range v {
+			resolved, err := r.interpolate(field, depth)
+			if err != nil {
+				return nil, err
+			}
+			result[key] = resolved
+		}
+		return result, nil
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			resolved, err := r.interpolate(item, depth)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = resolved
+		}
+		return result, nil
+	case string:
+		return r.interpolateString(v, depth)
+	}
+	return value, nil
+}
+
+// interpolateString replaces variables in the string. If the string consists of
+// a single variable, the value keeps its original type, e.g. number or map.
+func (r *resolver) interpolateString(value string, depth int) (interface{}, error) {
+	for ; depth <= maxResolveDepth; depth++ {
+		var matches [][]int
+		for _, match := range variable.FindAllStringSubmatchIndex(value, -1) {
+			// escaped expressions are kept until the string is resolved
+			if match[2] == match[3] {
+				matches = append(matches, match)
+			}
+		}
+		if len(matches) == 0 {
+			return strings.Replace(value, "$${", "${", -1), nil
+		}
+		if len(matches) == 1 && matches[0][0] == 0 && matches[0][1] == len(value) {
+			resolved, err := r.resolve(value[matches[0][4]:matches[0][5]])
+			if err != nil {
+				return nil, err
+			}
+			if str, ok := resolved.(string); ok {
+				value = str
+				continue
+			}
+			return r.interpolate(resolved, depth+1)
+		}
+
+		var result strings.Builder
+		var last int
+		for _, match := range matches {
+			resolved, err := r.resolve(value[match[4]:match[5]])
+			if err != nil {
+				return nil, err
+			}
+			switch resolved.(type) {
+			case map[interface{}]interface{}, []interface{}:
+				return nil, fmt.Errorf("variable %s is not a scalar value and can't be a part of the string", value[match[0]:match[1]])
+			}
+			result.WriteString(value[last:match[0]])
+			result.WriteString(fmt.Sprint(resolved))
+			last = match[1]
+		}
+		result.WriteString(value[last:])
+		value = result.String()
+	}
+	return nil, fmt.Errorf("variables nesting is too deep, check for reference loops")
+}
+
+// resolve returns the value of a single variable expression
+func (r *resolver) resolve(expression string) (interface{}, error) {
+	reference, fallback, hasFallback := expression, "", false
+	if i := strings.Index(expression, ","); i != -1 {
+		reference = expression[:i]
+		fallback = strings.Trim(strings.TrimSpace(expression[i+1:]), `"'`)
+		hasFallback = true
+	}
+	reference = strings.TrimSpace(reference)
+
+	value, found, err := r.lookup(reference)
+	if err != nil {
+		return nil, fmt.Errorf("variable ${%s}: %s", expression, err)
+	}
+	if found {
+		return value, nil
+	}
+	if hasFallback {
+		return fallback, nil
+	}
+	return nil, fmt.Errorf("variable ${%s} is not set", expression)
+}
+
+func (r *resolver) lookup(reference string) (interface{}, bool, error) {
+	switch {
+	case strings.HasPrefix(reference, "env:"):
+		value, found := os.LookupEnv(strings.TrimPrefix(reference, "env:"))
+		return value, found, nil
+	case reference == "opt:stage":
+		return r.stage, r.stage != "", nil
+	case strings.HasPrefix(reference, "self:"):
+		value, found := lookupPath(r.root, strings.TrimPrefix(reference, "self:"))
+		return value, found, nil
+	case strings.HasPrefix(reference, "file("):
+		match := fileVariable.FindStringSubmatch(reference)
+		if match == nil {
+			return nil, false, fmt.Errorf("file reference must be in file(path):key format")
+		}
+		tree, err := r.readFile(strings.TrimSpace(match[1]))
+		if err != nil {
+			return nil, false, err
+		}
+		value, found := lookupPath(tree, match[3])
+		return value, found, nil
+	}
+	return nil, false, fmt.Errorf("unknown variable source")
+}
+
+// readFile parses YAML file, relative paths are resolved against manifest directory
+func (r *resolver) readFile(path string) (interface{}, error) {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(r.workdir, path)
+	}
+	data, err := afero.ReadFile(Aos, path)
+	if err != nil {
+		return nil, err
+	}
+	var tree interface{}
+	if err := yaml.Unmarshal(data, &tree); err != nil {
+		return nil, fmt.Errorf("cannot parse %s: %s", path, err)
+	}
+	return tree, nil
+}
+
+// lookupPath returns the value with dot-separated path in YAML tree,
+// keys with dots must be quoted, e.g. functions."foo.bar".source.
+// Empty path returns the tree itself
+func lookupPath(tree interface{}, path string) (interface{}, bool) {
+	if path == "" {
+		return tree, tree != nil
+	}
+	for _, key := range splitPath(path) {
+		var found bool
+		if tree, found = lookupKey(tree, key); !found {
+			return nil, false
+		}
+	}
+	return tree, tree != nil
+}
+
+// lookupKey returns the value of the YAML map key
+func lookupKey(tree interface{}, key string) (interface{}, bool) {
+	node, ok := tree.(map[interface{}]interface{})
+	if !ok {
+		return nil, false
+	}
+	for k, v := range node {
+		if fmt.Sprint(k) == key {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// splitPath splits the path by dots that are not inside single or double quotes
+func splitPath(path string) []string {
+	var keys []string
+	var key strings.Builder
+	var quote rune
+	for _, c := range path {
+		switch {
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote != 0:
+			key.WriteRune(c)
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '.':
+			keys = append(keys, key.String())
+			key.Reset()
+		default:
+			key.WriteRune(c)
+		}
+	}
+	return append(keys, key.String())
+}
+
+// applyStage removes "stages" section from the manifest tree and merges
+// the selected stage provider and functions overrides into the manifest
+func applyStage(root map[interface{}]interface{}, stage string) error {
+	stages, _ := root["stages"].(map[interface{}]interface{})
+	delete(root, "stages")
+	if stage == "" {
+		return nil
+	}
+	overlay, found := lookupKey(stages, stage)
+	if !found {
+		return fmt.Errorf("stage %q is not defined in manifest", stage)
+	}
+	overrides, ok := overlay.(map[interface{}]interface{})
+	if !ok {
+		return fmt.Errorf("stage %q must be a map", stage)
+	}
+	for key, value := range overrides {
+		switch key {
+		case "provider", "functions":
+			root[key] = merge(root[key], value)
+		default:
+			return fmt.Errorf("stage %q: only provider and functions can be overridden, got %q", stage, key)
+		}
+	}
+	return nil
+}
+
+// merge recursively merges overlay maps into base, other values are replaced
+func merge(base, overlay interface{}) interface{} {
+	baseMap, ok := base.(map[interface{}]interface{})
+	if !ok {
+		return overlay
+	}
+	overlayMap, ok := overlay.(map[interface{}]interface{})
+	if !ok {
+		return overlay
+	}
+	result := make(map[interface{}]interface{}, len(baseMap))
+	for k, v := range baseMap {
+		result[k] = v
+	}
+	for k, v := range overlayMap {
+		result[k] = merge(result[k], v)
+	}
+	return result
+}
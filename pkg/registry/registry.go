@@ -0,0 +1,141 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/triggermesh/tm/pkg/client"
+)
+
+// timeout limits the time of registry requests
+const timeout = 30 * time.Second
+
+// dockerConfig is the structure of registry secret docker configs
+type dockerConfig struct {
+	Auths map[string]authn.AuthConfig
+}
+
+// registry secret keys with docker configs, push config is created by tm
+// and pull config is the standard kubernetes.io/dockerconfigjson secret key
+var secretConfigKeys = []string{"config.json", ".dockerconfigjson"}
+
+// ErrNotFound is returned when image is missing in the registry
+var ErrNotFound = errors.New("image not found")
+
+// Digest returns the digest of the image manifest in the registry.
+// Registry secret credentials from the namespace are used if the secret is set.
+func Digest(clientset *client.ConfigSet, namespace, image string) (string, error) {
+	ref, err := reference(clientset, image)
+	if err != nil {
+		return "", err
+	}
+	auth, err := credentials(clientset, namespace, ref.Context().RegistryStr())
+	if err != nil {
+		return "", err
+	}
+	rt := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           (&net.Dialer{Timeout: timeout}).DialContext,
+		ResponseHeaderTimeout: timeout,
+		TLSHandshakeTimeout:   timeout,
+		TLSClientConfig:       &tls.Config{InsecureSkipVerify: clientset.Registry.SkipTLS},
+	}
+	descriptor, err := remote.Get(ref, remote.WithAuth(auth), remote.WithTransport(rt))
+	if err != nil {
+		var terr *transport.Error
+		if errors.As(err, &terr) && terr.StatusCode == http.StatusNotFound {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+	return descriptor.Digest.String(), nil
+}
+
+// Pin replaces image tag with the digest
+func Pin(image, digest string) (string, error) {
+	if !strings.HasPrefix(digest, "sha256:") {
+		return "", fmt.Errorf("unsupported digest %q", digest)
+	}
+	ref, err := name.ParseReference(image, name.WeakValidation)
+	if err != nil {
+		return "", err
+	}
+	return ref.Context().Name() + "@" + digest, nil
+}
+
+// IsPinned returns true if image is referenced by digest
+func IsPinned(image string) bool {
+	return strings.Contains(image, "@sha256:")
+}
+
+// Host returns bare registry hostname of the docker config auths key,
+// e.g. "https://index.docker.io/v1/" becomes "index.docker.io"
+func Host(registry string) string {
+	host := strings.TrimPrefix(strings.TrimPrefix(registry, "https://"), "http://")
+	host = strings.SplitN(host, "/", 2)[0]
+	if r, err := name.NewRegistry(host, name.WeakValidation); err == nil {
+		return r.RegistryStr()
+	}
+	return host
+}
+
+func reference(clientset *client.ConfigSet, image string) (name.Reference, error) {
+	options := []name.Option{name.WeakValidation}
+	if clientset.Registry.SkipTLS {
+		options = append(options, name.Insecure)
+	}
+	return name.ParseReference(image, options...)
+}
+
+// credentials returns authenticator for the registry host from the registry secret
+func credentials(clientset *client.ConfigSet, namespace, host string) (authn.Authenticator, error) {
+	if clientset.Registry.Secret == "" {
+		return authn.Anonymous, nil
+	}
+	secret, err := clientset.Core.CoreV1().Secrets(namespace).Get(clientset.Registry.Secret, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range secretConfigKeys {
+		data, ok := secret.Data[key]
+		if !ok {
+			continue
+		}
+		var config dockerConfig
+		if err := json.Unmarshal(data, &config); err != nil {
+			return nil, fmt.Errorf("parsing %q secret %s: %s", secret.Name, key, err)
+		}
+		for registry, creds := range config.Auths {
+			if Host(registry) == Host(host) {
+				return authn.FromConfig(creds), nil
+			}
+		}
+	}
+	return authn.Anonymous, nil
+}
@@ -0,0 +1,80 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/triggermesh/tm/pkg/client/fake"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestHost(t *testing.T) {
+	testCases := map[string]string{
+		"https://index.docker.io/v1/": "index.docker.io",
+		"docker.io":                   "index.docker.io",
+		"http://localhost:5000":       "localhost:5000",
+		"gcr.io":                      "gcr.io",
+		"registry.example.com/path":   "registry.example.com",
+	}
+	for registry, host := range testCases {
+		assert.Equal(t, host, Host(registry), registry)
+	}
+}
+
+func TestFakeCredentials(t *testing.T) {
+	clientset := fake.NewConfigSet(
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "registry", Namespace: fake.Namespace},
+			Data: map[string][]byte{"config.json": []byte(`{"auths":{
+				"https://index.docker.io/v1/":{"username":"foo","password":"bar"},
+				"https://gcr.io":{"username":"baz","password":"qux"}}}`)},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "pull", Namespace: fake.Namespace},
+			Type:       corev1.SecretTypeDockerConfigJson,
+			Data: map[string][]byte{".dockerconfigjson": []byte(`{"auths":{
+				"quay.io":{"auth":"Zm9vOmJhcg=="}}}`)},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "empty", Namespace: fake.Namespace},
+		},
+	)
+
+	testCases := []struct {
+		secret string
+		host   string
+		want   authn.AuthConfig
+	}{
+		{secret: "registry", host: "index.docker.io", want: authn.AuthConfig{Username: "foo", Password: "bar"}},
+		{secret: "registry", host: "gcr.io", want: authn.AuthConfig{Username: "baz", Password: "qux"}},
+		{secret: "registry", host: "quay.io"},
+		{secret: "pull", host: "quay.io", want: authn.AuthConfig{Auth: "Zm9vOmJhcg=="}},
+		{secret: "pull", host: "gcr.io"},
+		{secret: "empty", host: "gcr.io"},
+	}
+	for _, tc := range testCases {
+		clientset.Registry.Secret = tc.secret
+		auth, err := credentials(clientset, fake.Namespace, tc.host)
+		require.NoError(t, err)
+		config, err := auth.Authorization()
+		require.NoError(t, err)
+		assert.Equal(t, tc.want, *config, "%s %s", tc.secret, tc.host)
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package broker
+
+import (
+	"fmt"
+
+	"github.com/ghodss/yaml"
+	"github.com/triggermesh/tm/pkg/client"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	eventingapi "knative.dev/eventing/pkg/apis/eventing/v1beta1"
+)
+
+// Deploy knative eventing broker
+func (b *Broker) Deploy(clientset *client.ConfigSet) error {
+	brokerObject := b.newObject()
+	if client.Dry {
+		res, err := yaml.Marshal(brokerObject)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s\n", res)
+		return nil
+	}
+	return b.createOrUpdate(brokerObject, clientset)
+}
+
+func (b *Broker) newObject() eventingapi.Broker {
+	return eventingapi.Broker{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Broker",
+			APIVersion: "eventing.knative.dev/v1beta1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      b.Name,
+			Namespace: b.Namespace,
+		},
+	}
+}
+
+func (b *Broker) createOrUpdate(brokerObject eventingapi.Broker, clientset *client.ConfigSet) error {
+	_, err := clientset.Eventing.EventingV1beta1().Brokers(b.Namespace).Create(&brokerObject)
+	if k8serrors.IsAlreadyExists(err) {
+		broker, err := clientset.Eventing.EventingV1beta1().Brokers(b.Namespace).Get(brokerObject.ObjectMeta.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		brokerObject.ObjectMeta.ResourceVersion = broker.GetResourceVersion()
+		brokerObject.ObjectMeta.Annotations = broker.GetAnnotations()
+		brokerObject.Spec = broker.Spec
+		_, err = clientset.Eventing.EventingV1beta1().Brokers(b.Namespace).Update(&brokerObject)
+		return err
+	}
+	return err
+}
@@ -0,0 +1,92 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package broker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/triggermesh/tm/pkg/client/fake"
+	"k8s.io/apimachinery/pkg/api/equality"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8stesting "k8s.io/client-go/testing"
+	eventingapi "knative.dev/eventing/pkg/apis/eventing/v1beta1"
+	eventingFake "knative.dev/eventing/pkg/client/clientset/versioned/fake"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+func TestFakeDeployGetListDelete(t *testing.T) {
+	clientset := fake.NewConfigSet()
+	b := &Broker{Name: "default", Namespace: fake.Namespace}
+
+	require.NoError(t, b.Deploy(clientset))
+	broker, err := b.Get(clientset)
+	require.NoError(t, err)
+	assert.Equal(t, "default", broker.Name)
+
+	list, err := b.List(clientset)
+	require.NoError(t, err)
+	assert.Len(t, list.Items, 1)
+
+	require.NoError(t, b.Delete(clientset))
+	_, err = b.Get(clientset)
+	assert.True(t, k8serrors.IsNotFound(err))
+	assert.True(t, k8serrors.IsNotFound(b.Delete(clientset)))
+}
+
+func TestFakeDeployExisting(t *testing.T) {
+	existing := &eventingapi.Broker{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "default",
+			Namespace:       fake.Namespace,
+			ResourceVersion: "1",
+			Annotations: map[string]string{
+				eventingapi.BrokerClassAnnotationKey: "MTChannelBasedBroker",
+			},
+		},
+		Spec: eventingapi.BrokerSpec{
+			Config: &duckv1.KReference{
+				APIVersion: "v1",
+				Kind:       "ConfigMap",
+				Name:       "config-br-default-channel",
+				Namespace:  "knative-eventing",
+			},
+		},
+	}
+	clientset := fake.NewConfigSet(existing)
+	// broker spec is immutable, webhook rejects the updates that change it
+	var updated bool
+	clientset.Eventing.(*eventingFake.Clientset).PrependReactor("update", "brokers", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		broker := action.(k8stesting.UpdateAction).GetObject().(*eventingapi.Broker)
+		if !equality.Semantic.DeepEqual(broker.Spec, existing.Spec) {
+			return true, nil, k8serrors.NewInvalid(schema.GroupKind{Group: "eventing.knative.dev", Kind: "Broker"}, broker.Name, nil)
+		}
+		updated = true
+		return false, nil, nil
+	})
+
+	b := &Broker{Name: "default", Namespace: fake.Namespace}
+	require.NoError(t, b.Deploy(clientset))
+	assert.True(t, updated)
+
+	broker, err := b.Get(clientset)
+	require.NoError(t, err)
+	assert.Equal(t, existing.Annotations, broker.Annotations)
+	assert.Equal(t, existing.Spec, broker.Spec)
+}
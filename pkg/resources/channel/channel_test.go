@@ -15,36 +15,23 @@
 package channel
 
 import (
-	"os"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
-	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/client/fake"
 )
 
 func TestList(t *testing.T) {
+	channelClient := fake.NewConfigSet()
 
-	namespace := "test-namespace"
-	if ns, ok := os.LookupEnv("NAMESPACE"); ok {
-		namespace = ns
-	}
-	channelClient, err := client.NewClient(client.ConfigPath(""))
-	assert.NoError(t, err)
-
-	channel := &Channel{Namespace: namespace}
+	channel := &Channel{Namespace: fake.Namespace}
 
-	_, err = channel.List(&channelClient)
+	_, err := channel.List(channelClient)
 	assert.NoError(t, err)
 }
 
 func TestBuild(t *testing.T) {
-
-	namespace := "test-namespace"
-	if ns, ok := os.LookupEnv("NAMESPACE"); ok {
-		namespace = ns
-	}
-	channelClient, err := client.NewClient(client.ConfigPath(""))
-	assert.NoError(t, err)
+	channelClient := fake.NewConfigSet()
 
 	testCases := []struct {
 		Name        string
@@ -58,10 +45,10 @@ func TestBuild(t *testing.T) {
 	for _, tc := range testCases {
 		channel := &Channel{
 			Name:      tc.Name,
-			Namespace: namespace,
+			Namespace: fake.Namespace,
 		}
 
-		err = channel.Deploy(&channelClient)
+		err := channel.Deploy(channelClient)
 		if err != nil {
 			if tc.ExpectedErr != "" {
 				assert.EqualError(t, err, tc.ExpectedErr)
@@ -70,11 +57,11 @@ func TestBuild(t *testing.T) {
 			t.Error(err)
 		}
 
-		ch, err := channel.Get(&channelClient)
+		ch, err := channel.Get(channelClient)
 		assert.NoError(t, err)
 		assert.Equal(t, tc.Name, ch.Name)
 
-		err = channel.Delete(&channelClient)
+		err = channel.Delete(channelClient)
 		assert.NoError(t, err)
 	}
 }
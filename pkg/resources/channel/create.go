@@ -16,17 +16,31 @@ package channel
 
 import (
 	"fmt"
+	"reflect"
 
 	"github.com/ghodss/yaml"
 	"github.com/triggermesh/tm/pkg/client"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	messagingapi "knative.dev/eventing/pkg/apis/messaging/v1beta1"
 )
 
+const defaultKind = "InMemoryChannel"
+
+// API versions of the known channel implementations
+var channelAPIVersions = map[string]string{
+	"InMemoryChannel": "messaging.knative.dev/v1beta1",
+	"KafkaChannel":    "messaging.knative.dev/v1alpha1",
+	"NatssChannel":    "messaging.knative.dev/v1alpha1",
+}
+
 // Deploy knative eventing channel
 func (c *Channel) Deploy(clientset *client.ConfigSet) error {
-	channelObject := c.newObject(clientset)
+	channelObject, err := c.newObject()
+	if err != nil {
+		return err
+	}
 	if client.Dry {
 		res, err := yaml.Marshal(channelObject)
 		if err != nil {
@@ -38,25 +52,92 @@ func (c *Channel) Deploy(clientset *client.ConfigSet) error {
 	return c.createOrUpdate(channelObject, clientset)
 }
 
-func (c *Channel) newObject(clientset *client.ConfigSet) messagingapi.InMemoryChannel {
-	return messagingapi.InMemoryChannel{
+func (c *Channel) newObject() (*messagingapi.Channel, error) {
+	template, err := c.template()
+	if err != nil {
+		return nil, err
+	}
+	return &messagingapi.Channel{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Channel",
+			APIVersion: "messaging.knative.dev/v1beta1",
+		},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      c.Name,
 			Namespace: c.Namespace,
 		},
+		Spec: messagingapi.ChannelSpec{
+			ChannelTemplate: template,
+		},
+	}, nil
+}
+
+// template returns channel implementation template
+func (c *Channel) template() (*messagingapi.ChannelTemplateSpec, error) {
+	kind := c.Kind
+	if kind == "" {
+		kind = defaultKind
+	}
+	apiVersion := c.APIVersion
+	if apiVersion == "" {
+		var ok bool
+		if apiVersion, ok = channelAPIVersions[kind]; !ok {
+			return nil, fmt.Errorf("unknown channel kind %q, API version must be set", kind)
+		}
+	}
+	template := &messagingapi.ChannelTemplateSpec{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       kind,
+			APIVersion: apiVersion,
+		},
 	}
+	if c.Spec != "" {
+		spec, err := yaml.YAMLToJSON([]byte(c.Spec))
+		if err != nil {
+			return nil, fmt.Errorf("parsing channel spec: %s", err)
+		}
+		template.Spec = &runtime.RawExtension{Raw: spec}
+	}
+	return template, nil
 }
 
-func (c *Channel) createOrUpdate(channelObject messagingapi.InMemoryChannel, clientset *client.ConfigSet) error {
-	_, err := clientset.Eventing.MessagingV1beta1().InMemoryChannels(c.Namespace).Create(&channelObject)
+// createOrUpdate creates the channel if it does not exist.
+// Channel template is immutable so existing channel can only be
+// "updated" with the same template.
+func (c *Channel) createOrUpdate(channelObject *messagingapi.Channel, clientset *client.ConfigSet) error {
+	_, err := clientset.Eventing.MessagingV1beta1().Channels(c.Namespace).Create(channelObject)
 	if k8serrors.IsAlreadyExists(err) {
-		channel, err := clientset.Eventing.MessagingV1beta1().InMemoryChannels(c.Namespace).Get(channelObject.ObjectMeta.Name, metav1.GetOptions{})
+		channel, err := clientset.Eventing.MessagingV1beta1().Channels(c.Namespace).Get(channelObject.ObjectMeta.Name, metav1.GetOptions{})
 		if err != nil {
 			return err
 		}
-		channelObject.ObjectMeta.ResourceVersion = channel.GetResourceVersion()
-		_, err = clientset.Eventing.MessagingV1beta1().InMemoryChannels(c.Namespace).Update(&channelObject)
-		return err
+		if !sameTemplate(channel.Spec.ChannelTemplate, channelObject.Spec.ChannelTemplate) {
+			return fmt.Errorf("channel %q already exists with different template, channel template cannot be changed", c.Name)
+		}
+		return nil
 	}
 	return err
 }
+
+func sameTemplate(existing, desired *messagingapi.ChannelTemplateSpec) bool {
+	if existing == nil || desired == nil {
+		return existing == desired
+	}
+	if existing.TypeMeta != desired.TypeMeta {
+		return false
+	}
+	if desired.Spec == nil {
+		return true
+	}
+	if existing.Spec == nil {
+		return false
+	}
+	var have, want interface{}
+	if err := yaml.Unmarshal(existing.Spec.Raw, &have); err != nil {
+		return false
+	}
+	if err := yaml.Unmarshal(desired.Spec.Raw, &want); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(have, want)
+}
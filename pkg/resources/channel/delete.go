@@ -18,10 +18,16 @@ package channel
 
 import (
 	"github.com/triggermesh/tm/pkg/client"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-// Delete removes knative inmemory channel object
+// Delete removes knative channel object. InMemoryChannel with the same name
+// is removed if channel does not exist for compatibility with previous versions.
 func (c *Channel) Delete(clientset *client.ConfigSet) error {
-	return clientset.Eventing.MessagingV1beta1().InMemoryChannels(c.Namespace).Delete(c.Name, &metav1.DeleteOptions{})
+	err := clientset.Eventing.MessagingV1beta1().Channels(c.Namespace).Delete(c.Name, &metav1.DeleteOptions{})
+	if k8serrors.IsNotFound(err) {
+		return clientset.Eventing.MessagingV1beta1().InMemoryChannels(c.Namespace).Delete(c.Name, &metav1.DeleteOptions{})
+	}
+	return err
 }
@@ -0,0 +1,152 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package channel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/triggermesh/tm/pkg/client/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	messagingapi "knative.dev/eventing/pkg/apis/messaging/v1beta1"
+)
+
+func TestTemplate(t *testing.T) {
+	testCases := []struct {
+		name       string
+		channel    Channel
+		kind       string
+		apiVersion string
+		spec       string
+		wantErr    bool
+	}{
+		{
+			name:       "default channel",
+			kind:       "InMemoryChannel",
+			apiVersion: "messaging.knative.dev/v1beta1",
+		}, {
+			name:       "kafka channel with spec",
+			channel:    Channel{Kind: "KafkaChannel", Spec: "numPartitions: 3"},
+			kind:       "KafkaChannel",
+			apiVersion: "messaging.knative.dev/v1alpha1",
+			spec:       `{"numPartitions":3}`,
+		}, {
+			name:    "unknown kind",
+			channel: Channel{Kind: "FooChannel"},
+			wantErr: true,
+		}, {
+			name:       "unknown kind with API version",
+			channel:    Channel{Kind: "FooChannel", APIVersion: "foo.dev/v1"},
+			kind:       "FooChannel",
+			apiVersion: "foo.dev/v1",
+		}, {
+			name:    "malformed spec",
+			channel: Channel{Spec: "{"},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			template, err := tc.channel.template()
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.kind, template.Kind)
+			assert.Equal(t, tc.apiVersion, template.APIVersion)
+			if tc.spec != "" {
+				assert.JSONEq(t, tc.spec, string(template.Spec.Raw))
+			}
+		})
+	}
+}
+
+func TestFakeDeployGetListDelete(t *testing.T) {
+	clientset := fake.NewConfigSet()
+	c := &Channel{
+		Name:      "foo",
+		Namespace: fake.Namespace,
+		Kind:      "KafkaChannel",
+		Spec:      `{"numPartitions": 3}`,
+	}
+	require.NoError(t, c.Deploy(clientset))
+
+	channel, err := c.Get(clientset)
+	require.NoError(t, err)
+	assert.Equal(t, "KafkaChannel", channel.Spec.ChannelTemplate.Kind)
+
+	c.Spec = "numPartitions: 3"
+	assert.NoError(t, c.Deploy(clientset))
+	c.Spec = "numPartitions: 5"
+	assert.Error(t, c.Deploy(clientset))
+
+	list, err := c.List(clientset)
+	require.NoError(t, err)
+	assert.Len(t, list.Items, 1)
+
+	require.NoError(t, c.Delete(clientset))
+	_, err = c.Get(clientset)
+	assert.Error(t, err)
+}
+
+func TestFakeDeleteInMemoryChannel(t *testing.T) {
+	clientset := fake.NewConfigSet(&messagingapi.InMemoryChannel{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: fake.Namespace},
+	})
+	c := &Channel{Name: "foo", Namespace: fake.Namespace}
+	require.NoError(t, c.Delete(clientset))
+	assert.Error(t, c.Delete(clientset))
+}
+
+func TestFakeGetListInMemoryChannel(t *testing.T) {
+	clientset := fake.NewConfigSet(
+		&messagingapi.InMemoryChannel{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: fake.Namespace},
+		},
+		&messagingapi.Channel{
+			ObjectMeta: metav1.ObjectMeta{Name: "bar", Namespace: fake.Namespace},
+		},
+		&messagingapi.InMemoryChannel{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "bar",
+				Namespace: fake.Namespace,
+				OwnerReferences: []metav1.OwnerReference{
+					{Kind: "Channel", Name: "bar"},
+				},
+			},
+		},
+	)
+	c := &Channel{Name: "foo", Namespace: fake.Namespace}
+
+	channel, err := c.Get(clientset)
+	require.NoError(t, err)
+	assert.Equal(t, "foo", channel.Name)
+	assert.Equal(t, "InMemoryChannel", channel.Spec.ChannelTemplate.Kind)
+
+	list, err := c.List(clientset)
+	require.NoError(t, err)
+	names := []string{}
+	for _, item := range list.Items {
+		names = append(names, item.Name)
+	}
+	assert.ElementsMatch(t, []string{"foo", "bar"}, names)
+
+	c.Name = "baz"
+	_, err = c.Get(clientset)
+	assert.Error(t, err)
+}
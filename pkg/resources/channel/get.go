@@ -17,12 +17,13 @@ package channel
 import (
 	"github.com/triggermesh/tm/pkg/client"
 	"github.com/triggermesh/tm/pkg/printer"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	messagingapi "knative.dev/eventing/pkg/apis/messaging/v1beta1"
 )
 
 // GetObject converts k8s object into printable structure
-func (c *Channel) GetObject(service *messagingapi.InMemoryChannel) printer.Object {
+func (c *Channel) GetObject(channel *messagingapi.Channel) printer.Object {
 	return printer.Object{
 		Fields: map[string]interface{}{
 			"Kind":              metav1.TypeMeta{}.Kind,
@@ -30,13 +31,47 @@ func (c *Channel) GetObject(service *messagingapi.InMemoryChannel) printer.Objec
 			"Namespace":         metav1.ObjectMeta{}.Namespace,
 			"Name":              metav1.ObjectMeta{}.Name,
 			"CreationTimestamp": metav1.Time{},
-			"Status":            messagingapi.InMemoryChannelStatus{},
+			"Spec":              messagingapi.ChannelSpec{},
+			"Status":            messagingapi.ChannelStatus{},
 		},
-		K8sObject: service,
+		K8sObject: channel,
 	}
 }
 
-// Get returns k8s object
-func (c *Channel) Get(clientset *client.ConfigSet) (*messagingapi.InMemoryChannel, error) {
-	return clientset.Eventing.MessagingV1beta1().InMemoryChannels(c.Namespace).Get(c.Name, metav1.GetOptions{})
+// Get returns k8s object. InMemoryChannel with the same name is returned
+// if channel does not exist for compatibility with previous versions.
+func (c *Channel) Get(clientset *client.ConfigSet) (*messagingapi.Channel, error) {
+	channel, err := clientset.Eventing.MessagingV1beta1().Channels(c.Namespace).Get(c.Name, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		imc, imcErr := clientset.Eventing.MessagingV1beta1().InMemoryChannels(c.Namespace).Get(c.Name, metav1.GetOptions{})
+		if imcErr != nil {
+			return nil, err
+		}
+		return fromInMemoryChannel(imc), nil
+	}
+	return channel, err
+}
+
+// fromInMemoryChannel wraps InMemoryChannel into generic channel object
+// so that both can be printed the same way
+func fromInMemoryChannel(imc *messagingapi.InMemoryChannel) *messagingapi.Channel {
+	return &messagingapi.Channel{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "InMemoryChannel",
+			APIVersion: channelAPIVersions["InMemoryChannel"],
+		},
+		ObjectMeta: *imc.ObjectMeta.DeepCopy(),
+		Spec: messagingapi.ChannelSpec{
+			ChannelTemplate: &messagingapi.ChannelTemplateSpec{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "InMemoryChannel",
+					APIVersion: channelAPIVersions["InMemoryChannel"],
+				},
+			},
+			ChannelableSpec: *imc.Spec.ChannelableSpec.DeepCopy(),
+		},
+		Status: messagingapi.ChannelStatus{
+			ChannelableStatus: *imc.Status.ChannelableStatus.DeepCopy(),
+		},
+	}
 }
@@ -20,17 +20,19 @@ import (
 
 	"github.com/triggermesh/tm/pkg/client"
 	"github.com/triggermesh/tm/pkg/printer"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/duration"
 	messagingapi "knative.dev/eventing/pkg/apis/messaging/v1beta1"
 )
 
 // GetTable converts k8s list instance into printable object
-func (c *Channel) GetTable(list *messagingapi.InMemoryChannelList) printer.Table {
+func (c *Channel) GetTable(list *messagingapi.ChannelList) printer.Table {
 	table := printer.Table{
 		Headers: []string{
 			"Namespace",
 			"Name",
+			"Kind",
 			"Url",
 			"Age",
 			"Ready",
@@ -45,10 +47,17 @@ func (c *Channel) GetTable(list *messagingapi.InMemoryChannelList) printer.Table
 	return table
 }
 
-func (c *Channel) row(item *messagingapi.InMemoryChannel) []string {
+func (c *Channel) row(item *messagingapi.Channel) []string {
 	name := item.Name
 	namespace := item.Namespace
-	url := item.Status.Address.URL.String()
+	kind := ""
+	if item.Spec.ChannelTemplate != nil {
+		kind = item.Spec.ChannelTemplate.Kind
+	}
+	url := ""
+	if item.Status.Address != nil {
+		url = item.Status.Address.URL.String()
+	}
 	age := duration.HumanDuration(time.Since(item.GetCreationTimestamp().Time))
 	ready := fmt.Sprintf("%v", item.Status.IsReady())
 	readyCondition := item.Status.GetCondition(messagingapi.ChannelConditionReady)
@@ -60,6 +69,7 @@ func (c *Channel) row(item *messagingapi.InMemoryChannel) []string {
 	row := []string{
 		namespace,
 		name,
+		kind,
 		url,
 		age,
 		ready,
@@ -69,7 +79,33 @@ func (c *Channel) row(item *messagingapi.InMemoryChannel) []string {
 	return row
 }
 
-// List returns list of knative build objects
-func (c *Channel) List(clientset *client.ConfigSet) (*messagingapi.InMemoryChannelList, error) {
-	return clientset.Eventing.MessagingV1beta1().InMemoryChannels(c.Namespace).List(metav1.ListOptions{})
+// List returns list of knative channel objects together with
+// InMemoryChannels that are not backing any of the listed channels
+func (c *Channel) List(clientset *client.ConfigSet) (*messagingapi.ChannelList, error) {
+	list, err := clientset.Eventing.MessagingV1beta1().Channels(c.Namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	imcs, err := clientset.Eventing.MessagingV1beta1().InMemoryChannels(c.Namespace).List(metav1.ListOptions{})
+	if k8serrors.IsNotFound(err) {
+		return list, nil
+	} else if err != nil {
+		return nil, err
+	}
+	for _, imc := range imcs.Items {
+		if ownedByChannel(&imc) {
+			continue
+		}
+		list.Items = append(list.Items, *fromInMemoryChannel(&imc))
+	}
+	return list, nil
+}
+
+func ownedByChannel(imc *messagingapi.InMemoryChannel) bool {
+	for _, owner := range imc.OwnerReferences {
+		if owner.Kind == "Channel" {
+			return true
+		}
+	}
+	return false
 }
@@ -14,8 +14,14 @@
 
 package channel
 
-// Channel represents knative channel object
+// Channel represents knative eventing channel object.
+// Kind and APIVersion define the channel implementation
+// that is created from the template with the given Spec.
 type Channel struct {
-	Name      string
-	Namespace string
+	Name       string
+	Namespace  string
+	Kind       string
+	APIVersion string
+	// Spec of the channel implementation in YAML or JSON format
+	Spec string
 }
@@ -15,53 +15,45 @@
 package configuration
 
 import (
-	"os"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
-	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/client/fake"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
 )
 
 func TestList(t *testing.T) {
+	testClient := fake.NewConfigSet(&servingv1.Configuration{
+		ObjectMeta: metav1.ObjectMeta{Name: "bar", Namespace: fake.Namespace},
+	})
 
-	namespace := "test-namespace"
-	if ns, ok := os.LookupEnv("NAMESPACE"); ok {
-		namespace = ns
-	}
-	configurationClient, err := client.NewClient(client.ConfigPath(""))
-	assert.NoError(t, err)
-
-	config := &Configuration{Name: "Foo", Namespace: namespace}
+	config := &Configuration{Name: "Foo", Namespace: fake.Namespace}
 
-	_, err = config.List(&configurationClient)
+	list, err := config.List(testClient)
 	assert.NoError(t, err)
+	assert.Len(t, list.Items, 1)
 }
 
 func TestGet(t *testing.T) {
+	testClient := fake.NewConfigSet()
 
-	namespace := "test-namespace"
-	if ns, ok := os.LookupEnv("NAMESPACE"); ok {
-		namespace = ns
-	}
-	configurationClient, err := client.NewClient(client.ConfigPath(""))
-	assert.NoError(t, err)
-
-	config := &Configuration{Name: "Foo", Namespace: namespace}
-	result, err := config.Get(&configurationClient)
-	assert.Error(t, err)
-	assert.Equal(t, "", result.Name)
+	config := &Configuration{Name: "Foo", Namespace: fake.Namespace}
+	_, err := config.Get(testClient)
+	assert.True(t, k8serrors.IsNotFound(err))
 }
 
 func TestDelete(t *testing.T) {
+	testClient := fake.NewConfigSet(&servingv1.Configuration{
+		ObjectMeta: metav1.ObjectMeta{Name: "bar", Namespace: fake.Namespace},
+	})
 
-	namespace := "test-namespace"
-	if ns, ok := os.LookupEnv("NAMESPACE"); ok {
-		namespace = ns
-	}
-	configurationClient, err := client.NewClient(client.ConfigPath(""))
-	assert.NoError(t, err)
-
-	config := &Configuration{Name: "Foo", Namespace: namespace}
-	err = config.Delete(&configurationClient)
+	config := &Configuration{Name: "Foo", Namespace: fake.Namespace}
+	err := config.Delete(testClient)
 	assert.Error(t, err)
+
+	config.Name = "bar"
+	err = config.Delete(testClient)
+	assert.NoError(t, err)
 }
@@ -20,5 +20,5 @@ import (
 )
 
 func (c *Configuration) Delete(clientset *client.ConfigSet) error {
-	return clientset.Serving.ServingV1alpha1().Configurations(c.Namespace).Delete(c.Name, &metav1.DeleteOptions{})
+	return clientset.Serving.ServingV1().Configurations(c.Namespace).Delete(c.Name, &metav1.DeleteOptions{})
 }
@@ -0,0 +1,98 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credential
+
+import (
+	"fmt"
+
+	"github.com/triggermesh/tm/pkg/client"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DeleteRegistryCreds removes registry credentials secret and its references from the namespace service accounts
+func (c *RegistryCreds) DeleteRegistryCreds(clientset *client.ConfigSet) error {
+	return deleteCredentials(clientset, c.Namespace, c.Name, registryType)
+}
+
+// DeleteGitCreds removes git credentials secret and its references from the namespace service accounts
+func (g *GitCreds) DeleteGitCreds(clientset *client.ConfigSet) error {
+	return deleteCredentials(clientset, g.Namespace, g.Name, gitType)
+}
+
+// deleteCredentials deletes the secret if it contains credentials of the requested type
+// and cleans up service accounts. References to already deleted secret are removed as well.
+func deleteCredentials(clientset *client.ConfigSet, namespace, name, credType string) error {
+	var notFound error
+	secret, err := clientset.Core.CoreV1().Secrets(namespace).Get(name, metav1.GetOptions{})
+	switch {
+	case k8serrors.IsNotFound(err):
+		notFound = err
+	case err != nil:
+		return err
+	case credentialsType(secret) != credType:
+		return fmt.Errorf("secret %q does not contain %s credentials", name, credType)
+	default:
+		if err := clientset.Core.CoreV1().Secrets(namespace).Delete(name, &metav1.DeleteOptions{}); err != nil {
+			return err
+		}
+	}
+
+	accounts, err := clientset.Core.CoreV1().ServiceAccounts(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	var updated int
+	for i := range accounts.Items {
+		sa := &accounts.Items[i]
+		if !removeReferences(sa, name) {
+			continue
+		}
+		if _, err := clientset.Core.CoreV1().ServiceAccounts(namespace).Update(sa); err != nil {
+			return err
+		}
+		updated++
+	}
+	if notFound != nil && updated == 0 {
+		return notFound
+	}
+	return nil
+}
+
+// removeReferences removes secret from service account image pull secrets and secrets lists.
+// Returns true if service account was changed.
+func removeReferences(sa *corev1.ServiceAccount, name string) bool {
+	var changed bool
+	pullSecrets := []corev1.LocalObjectReference{}
+	for _, v := range sa.ImagePullSecrets {
+		if v.Name == name {
+			changed = true
+			continue
+		}
+		pullSecrets = append(pullSecrets, v)
+	}
+	secrets := []corev1.ObjectReference{}
+	for _, v := range sa.Secrets {
+		if v.Name == name {
+			changed = true
+			continue
+		}
+		secrets = append(secrets, v)
+	}
+	sa.ImagePullSecrets = pullSecrets
+	sa.Secrets = secrets
+	return changed
+}
@@ -0,0 +1,178 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credential
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// execCommand creates credential helper command, replaced in tests
+var execCommand = exec.Command
+
+// localDockerConfig is the part of docker CLI config.json with registry credentials
+type localDockerConfig struct {
+	Auths map[string]struct {
+		Auth     string `json:"auth"`
+		Username string `json:"username"`
+		Password string `json:"password"`
+	} `json:"auths"`
+	CredsStore  string            `json:"credsStore"`
+	CredHelpers map[string]string `json:"credHelpers"`
+}
+
+// DefaultDockerConfig returns the path of docker CLI config file
+func DefaultDockerConfig() string {
+	if dir := os.Getenv("DOCKER_CONFIG"); dir != "" {
+		return filepath.Join(dir, "config.json")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = "."
+	}
+	return filepath.Join(home, ".docker", "config.json")
+}
+
+// dockerConfigAuths reads registries credentials from docker CLI config.
+// If registry host is not set and config has several registries, user is asked to pick them.
+func (c *RegistryCreds) dockerConfigAuths(input io.Reader) (map[string]registryAuth, error) {
+	data, err := ioutil.ReadFile(c.FromDockerConfig)
+	if err != nil {
+		return nil, err
+	}
+	var config localDockerConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("parsing %q: %s", c.FromDockerConfig, err)
+	}
+
+	var hosts []string
+	for host := range config.Auths {
+		hosts = append(hosts, host)
+	}
+	for host := range config.CredHelpers {
+		if _, ok := config.Auths[host]; !ok {
+			hosts = append(hosts, host)
+		}
+	}
+	if len(hosts) == 0 {
+		return nil, fmt.Errorf("no registries found in %q", c.FromDockerConfig)
+	}
+	sort.Strings(hosts)
+
+	selected := hosts
+	if c.Host != "" {
+		selected = []string{c.Host}
+	} else if len(hosts) > 1 {
+		if selected, err = selectHosts(hosts, input); err != nil {
+			return nil, err
+		}
+	}
+
+	auths := make(map[string]registryAuth)
+	for _, host := range selected {
+		auth, err := config.credentials(host)
+		if err != nil {
+			return nil, fmt.Errorf("%s credentials: %s", host, err)
+		}
+		auths[host] = auth
+	}
+	return auths, nil
+}
+
+// credentials returns registry credentials stored in the config or in the credential helper
+func (config localDockerConfig) credentials(host string) (registryAuth, error) {
+	if helper, ok := config.CredHelpers[host]; ok {
+		return helperCredentials(helper, host)
+	}
+	if auth, ok := config.Auths[host]; ok {
+		if auth.Username != "" {
+			return registryAuth{Username: auth.Username, Password: auth.Password}, nil
+		}
+		if auth.Auth != "" {
+			decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+			if err != nil {
+				return registryAuth{}, err
+			}
+			userpass := strings.SplitN(string(decoded), ":", 2)
+			if len(userpass) != 2 {
+				return registryAuth{}, fmt.Errorf("auth is not in username:password format")
+			}
+			return registryAuth{Username: userpass[0], Password: userpass[1]}, nil
+		}
+	}
+	if config.CredsStore != "" {
+		return helperCredentials(config.CredsStore, host)
+	}
+	return registryAuth{}, fmt.Errorf("not found in docker config")
+}
+
+// helperCredentials runs docker credential helper to get registry credentials
+func helperCredentials(helper, host string) (registryAuth, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := execCommand("docker-credential-"+helper, "get")
+	cmd.Stdin = strings.NewReader(host)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		// helpers print errors to stdout
+		message := strings.TrimSpace(stdout.String() + stderr.String())
+		return registryAuth{}, fmt.Errorf("docker-credential-%s: %s: %s", helper, err, message)
+	}
+	var creds struct {
+		Username string
+		Secret   string
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &creds); err != nil {
+		return registryAuth{}, fmt.Errorf("docker-credential-%s output: %s", helper, err)
+	}
+	return registryAuth{Username: creds.Username, Password: creds.Secret}, nil
+}
+
+// selectHosts asks user to pick registries from the list
+func selectHosts(hosts []string, input io.Reader) ([]string, error) {
+	fmt.Println("Registries found in docker config:")
+	for i, host := range hosts {
+		fmt.Printf("%d) %s\n", i+1, host)
+	}
+	fmt.Print("Registries to import (comma separated numbers, empty for all): ")
+	text, err := bufio.NewReader(input).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return hosts, nil
+	}
+	var selected []string
+	for _, field := range strings.Split(text, ",") {
+		i, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || i < 1 || i > len(hosts) {
+			return nil, fmt.Errorf("invalid registry number %q", strings.TrimSpace(field))
+		}
+		selected = append(selected, hosts[i-1])
+	}
+	return selected, nil
+}
@@ -0,0 +1,360 @@
+package credential
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/triggermesh/tm/pkg/client/fake"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func defaultServiceAccount() *corev1.ServiceAccount {
+	return &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: fake.Namespace},
+	}
+}
+
+func secretConfig(t *testing.T, secret *corev1.Secret, key string) dockerConfig {
+	var config dockerConfig
+	require.NoError(t, json.Unmarshal(secret.Data[key], &config))
+	return config
+}
+
+func TestFakeCreateRegistryCreds(t *testing.T) {
+	clientset := fake.NewConfigSet(defaultServiceAccount())
+	c := RegistryCreds{
+		Name:      "registry",
+		Namespace: fake.Namespace,
+		Host:      "gcr.io",
+		ProjectID: "foo",
+		Username:  "_json_key",
+		Password:  "secret",
+	}
+	require.NoError(t, c.CreateRegistryCreds(clientset))
+
+	secret, err := clientset.Core.CoreV1().Secrets(fake.Namespace).Get("registry", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, corev1.SecretTypeDockerConfigJson, secret.Type)
+	assert.Equal(t, dockerConfig{
+		Project:      "foo",
+		PushRegistry: "gcr.io",
+		Auths:        map[string]registryAuth{"gcr.io": {Username: "_json_key", Password: "secret"}},
+	}, secretConfig(t, secret, pushConfig))
+
+	sa, err := clientset.Core.CoreV1().ServiceAccounts(fake.Namespace).Get("default", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []corev1.LocalObjectReference{{Name: "registry"}}, sa.ImagePullSecrets)
+
+	// pull only registry is added to the pull config, push registry is not changed
+	c = RegistryCreds{
+		Name:      "registry",
+		Namespace: fake.Namespace,
+		Host:      "registry.example.com",
+		Username:  "bar",
+		Password:  "baz",
+		Pull:      true,
+		Append:    true,
+	}
+	require.NoError(t, c.CreateRegistryCreds(clientset))
+	secret, err = clientset.Core.CoreV1().Secrets(fake.Namespace).Get("registry", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Len(t, secretConfig(t, secret, pullConfig).Auths, 2)
+	assert.Len(t, secretConfig(t, secret, pushConfig).Auths, 1)
+
+	// appended registry is selected for push
+	c.Pull = false
+	c.PushRegistry = "registry.example.com"
+	require.NoError(t, c.CreateRegistryCreds(clientset))
+	secret, err = clientset.Core.CoreV1().Secrets(fake.Namespace).Get("registry", metav1.GetOptions{})
+	require.NoError(t, err)
+	config := secretConfig(t, secret, pushConfig)
+	assert.Len(t, config.Auths, 2)
+	assert.Equal(t, "registry.example.com", config.PushRegistry)
+	assert.Equal(t, "foo", config.Project)
+
+	// unknown push registry
+	c.PushRegistry = "quay.io"
+	assert.Error(t, c.CreateRegistryCreds(clientset))
+
+	// without append mode secret credentials are replaced
+	c = RegistryCreds{
+		Name:      "registry",
+		Namespace: fake.Namespace,
+		Host:      "quay.io",
+		Username:  "qux",
+		Password:  "quux",
+	}
+	require.NoError(t, c.CreateRegistryCreds(clientset))
+	secret, err = clientset.Core.CoreV1().Secrets(fake.Namespace).Get("registry", metav1.GetOptions{})
+	require.NoError(t, err)
+	for _, key := range []string{pullConfig, pushConfig} {
+		config := secretConfig(t, secret, key)
+		assert.Equal(t, []string{"quay.io"}, hosts(config), key)
+	}
+}
+
+func TestFakeCreateRegistryCredsServiceAccount(t *testing.T) {
+	builder := &corev1.ServiceAccount{
+		ObjectMeta:       metav1.ObjectMeta{Name: "builder", Namespace: fake.Namespace},
+		ImagePullSecrets: []corev1.LocalObjectReference{{Name: "existing"}},
+	}
+	clientset := fake.NewConfigSet(defaultServiceAccount(), builder)
+	c := RegistryCreds{
+		Name:           "registry",
+		Namespace:      fake.Namespace,
+		Host:           "gcr.io",
+		Username:       "foo",
+		Password:       "bar",
+		ServiceAccount: "builder",
+	}
+	require.NoError(t, c.CreateRegistryCreds(clientset))
+	// repeated call does not duplicate the reference
+	require.NoError(t, c.CreateRegistryCreds(clientset))
+
+	sa, err := clientset.Core.CoreV1().ServiceAccounts(fake.Namespace).Get("builder", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []corev1.LocalObjectReference{{Name: "existing"}, {Name: "registry"}}, sa.ImagePullSecrets)
+	sa, err = clientset.Core.CoreV1().ServiceAccounts(fake.Namespace).Get("default", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, sa.ImagePullSecrets)
+}
+
+func hosts(config dockerConfig) []string {
+	var hosts []string
+	for host := range config.Auths {
+		hosts = append(hosts, host)
+	}
+	return hosts
+}
+
+const localDockerConfigJSON = `{
+  "auths": {
+    "https://index.docker.io/v1/": {"auth": "Zm9vOmJhcg=="},
+    "registry.example.com": {},
+    "quay.io": {"username": "qux", "password": "quux"}
+  },
+  "credsStore": "desktop",
+  "credHelpers": {
+    "gcr.io": "gcloud"
+  }
+}`
+
+func TestFakeCreateRegistryCredsFromDockerConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tm-docker-config")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "config.json")
+	require.NoError(t, ioutil.WriteFile(path, []byte(localDockerConfigJSON), 0600))
+
+	var helpers []string
+	execCommand = func(name string, args ...string) *exec.Cmd {
+		helpers = append(helpers, name)
+		return exec.Command("echo", `{"ServerURL":"","Username":"`+strings.TrimPrefix(name, "docker-credential-")+`","Secret":"token"}`)
+	}
+	defer func() { execCommand = exec.Command }()
+
+	c := RegistryCreds{FromDockerConfig: path}
+	auths, err := c.dockerConfigAuths(strings.NewReader("\n"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]registryAuth{
+		"https://index.docker.io/v1/": {Username: "foo", Password: "bar"},
+		"registry.example.com":        {Username: "desktop", Password: "token"},
+		"quay.io":                     {Username: "qux", Password: "quux"},
+		"gcr.io":                      {Username: "gcloud", Password: "token"},
+	}, auths)
+	assert.ElementsMatch(t, []string{"docker-credential-desktop", "docker-credential-gcloud"}, helpers)
+
+	// hosts are listed in alphabetical order
+	auths, err = c.dockerConfigAuths(strings.NewReader("1, 3\n"))
+	require.NoError(t, err)
+	assert.Len(t, auths, 2)
+	assert.Contains(t, auths, "gcr.io")
+	assert.Contains(t, auths, "quay.io")
+
+	_, err = c.dockerConfigAuths(strings.NewReader("5\n"))
+	assert.EqualError(t, err, `invalid registry number "5"`)
+
+	clientset := fake.NewConfigSet(defaultServiceAccount())
+	c = RegistryCreds{
+		Name:             "registry",
+		Namespace:        fake.Namespace,
+		Host:             "quay.io",
+		FromDockerConfig: path,
+		Push:             true,
+	}
+	require.NoError(t, c.CreateRegistryCreds(clientset))
+	secret, err := clientset.Core.CoreV1().Secrets(fake.Namespace).Get("registry", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, dockerConfig{
+		PushRegistry: "quay.io",
+		Auths:        map[string]registryAuth{"quay.io": {Username: "qux", Password: "quux"}},
+	}, secretConfig(t, secret, pushConfig))
+	assert.Equal(t, "{}", string(secret.Data[pullConfig]))
+}
+
+func TestFakeCreateGitCreds(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tm-git-creds")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	key := filepath.Join(dir, "id_rsa")
+	knownHosts := filepath.Join(dir, "known_hosts")
+	require.NoError(t, ioutil.WriteFile(key, []byte("private key\n"), 0600))
+	require.NoError(t, ioutil.WriteFile(knownHosts, []byte("github.com ssh-rsa AAAA\n"), 0644))
+
+	clientset := fake.NewConfigSet(defaultServiceAccount(), &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: "builder", Namespace: fake.Namespace},
+	})
+
+	ssh := GitCreds{Namespace: fake.Namespace, Host: "github.com", Key: key, KnownHosts: knownHosts}
+	require.NoError(t, ssh.CreateGitCreds(clientset))
+	secret, err := clientset.Core.CoreV1().Secrets(fake.Namespace).Get("git-ssh-key", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, corev1.SecretTypeSSHAuth, secret.Type)
+	assert.Equal(t, map[string]string{"tekton.dev/git-0": "github.com"}, secret.Annotations)
+	assert.Equal(t, "private key\n", string(secret.Data["ssh-privatekey"]))
+	assert.Equal(t, "github.com ssh-rsa AAAA\n", string(secret.Data["known_hosts"]))
+
+	https := GitCreds{
+		Name:           "gitlab",
+		Namespace:      fake.Namespace,
+		Host:           "https://gitlab.example.com/",
+		Password:       "token",
+		ServiceAccount: "builder",
+	}
+	require.NoError(t, https.CreateGitCreds(clientset))
+	// repeated call updates the secret without duplicating service account reference
+	require.NoError(t, https.CreateGitCreds(clientset))
+	secret, err = clientset.Core.CoreV1().Secrets(fake.Namespace).Get("gitlab", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, corev1.SecretTypeBasicAuth, secret.Type)
+	assert.Equal(t, map[string]string{"tekton.dev/git-0": "https://gitlab.example.com"}, secret.Annotations)
+	assert.Equal(t, "git", string(secret.Data["username"]))
+	assert.Equal(t, "token", string(secret.Data["password"]))
+
+	sa, err := clientset.Core.CoreV1().ServiceAccounts(fake.Namespace).Get("default", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []corev1.ObjectReference{{Name: "git-ssh-key", Namespace: fake.Namespace}}, sa.Secrets)
+	sa, err = clientset.Core.CoreV1().ServiceAccounts(fake.Namespace).Get("builder", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []corev1.ObjectReference{{Name: "gitlab", Namespace: fake.Namespace}}, sa.Secrets)
+
+	// credentials type change
+	ssh.Name = "gitlab"
+	ssh.ServiceAccount = "builder"
+	require.NoError(t, ssh.CreateGitCreds(clientset))
+	secret, err = clientset.Core.CoreV1().Secrets(fake.Namespace).Get("gitlab", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, corev1.SecretTypeSSHAuth, secret.Type)
+	assert.NotContains(t, secret.Data, "password")
+
+	invalid := GitCreds{Namespace: fake.Namespace, Username: "foo"}
+	assert.Error(t, invalid.CreateGitCreds(clientset))
+}
+
+func TestFakeListDeleteCredentials(t *testing.T) {
+	sa := defaultServiceAccount()
+	sa.Secrets = []corev1.ObjectReference{{Name: "removed"}}
+	// secret created before credentials labeling
+	legacy := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "legacy",
+			Namespace:   fake.Namespace,
+			Annotations: map[string]string{"tekton.dev/git-0": "github.com"},
+		},
+		Type: corev1.SecretTypeSSHAuth,
+	}
+	other := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: fake.Namespace},
+		Type:       corev1.SecretTypeOpaque,
+	}
+	clientset := fake.NewConfigSet(sa, legacy, other)
+
+	registry := RegistryCreds{
+		Name:      "registry",
+		Namespace: fake.Namespace,
+		Host:      "gcr.io",
+		Username:  "_json_key",
+		Password:  "secret",
+	}
+	require.NoError(t, registry.CreateRegistryCreds(clientset))
+	git := GitCreds{
+		Name:      "gitlab",
+		Namespace: fake.Namespace,
+		Host:      "gitlab.com",
+		Username:  "foo",
+		Password:  "token",
+	}
+	require.NoError(t, git.CreateGitCreds(clientset))
+
+	cr := Credential{Namespace: fake.Namespace}
+	list, err := cr.List(clientset)
+	require.NoError(t, err)
+	require.Len(t, list, 4)
+	assert.Equal(t, Credential{
+		Name:            "gitlab",
+		Namespace:       fake.Namespace,
+		Type:            "git",
+		Hosts:           []string{"https://gitlab.com"},
+		Usernames:       []string{"***"},
+		ServiceAccounts: []string{"default"},
+	}, list[0])
+	assert.Equal(t, "legacy", list[1].Name)
+	assert.Equal(t, "git", list[1].Type)
+	assert.Empty(t, list[1].ServiceAccounts)
+	assert.Equal(t, Credential{
+		Name:         "registry",
+		Namespace:    fake.Namespace,
+		Type:         "registry",
+		Hosts:        []string{"gcr.io"},
+		PushRegistry: "gcr.io",
+		Usernames:    []string{"_j***ey"},
+		// default service account references registry secret in image pull secrets
+		ServiceAccounts: []string{"default"},
+	}, list[2])
+	assert.Equal(t, Credential{
+		Name:            "removed",
+		Namespace:       fake.Namespace,
+		ServiceAccounts: []string{"default"},
+		Missing:         true,
+	}, list[3])
+
+	cr.Name = "other"
+	_, err = cr.Get(clientset)
+	assert.Error(t, err)
+
+	// secret type is checked before deletion
+	registry.Name = "gitlab"
+	assert.Error(t, registry.DeleteRegistryCreds(clientset))
+
+	require.NoError(t, git.DeleteGitCreds(clientset))
+	registry.Name = "registry"
+	require.NoError(t, registry.DeleteRegistryCreds(clientset))
+	// dangling references are removed even though the secret does not exist
+	git.Name = "removed"
+	require.NoError(t, git.DeleteGitCreds(clientset))
+	assert.Error(t, git.DeleteGitCreds(clientset))
+
+	sa, err = clientset.Core.CoreV1().ServiceAccounts(fake.Namespace).Get("default", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, sa.Secrets)
+	assert.Empty(t, sa.ImagePullSecrets)
+
+	list, err = cr.List(clientset)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "legacy", list[0].Name)
+}
+
+func TestMask(t *testing.T) {
+	assert.Equal(t, "", mask(""))
+	assert.Equal(t, "***", mask("foo"))
+	assert.Equal(t, "_j***ey", mask("_json_key"))
+}
@@ -17,7 +17,9 @@ package credential
 import (
 	"bufio"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"strings"
 
 	"github.com/triggermesh/tm/pkg/client"
 	corev1 "k8s.io/api/core/v1"
@@ -25,68 +27,147 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-var secretName = "git-ssh-key"
+const (
+	// gitAnnotation is the tekton annotation with the host that git credentials are used for
+	gitAnnotation = "tekton.dev/git-0"
+	// defaultGitUsername is used with access tokens if username is not set
+	defaultGitUsername = "git"
+)
+
+var (
+	// secretName is the default name of SSH key secret
+	secretName = "git-ssh-key"
+	// basicAuthSecretName is the default name of HTTPS credentials secret
+	basicAuthSecretName = "git-basic-auth"
+)
 
+// CreateGitCreds creates Secret with git SSH key or HTTPS credentials for the host
+// and adds it to the service account secrets so that tekton could use it to fetch sources.
 func (g *GitCreds) CreateGitCreds(clientset *client.ConfigSet) error {
-	if len(g.Key) == 0 {
-		g.readStdin()
+	secret, err := g.secret()
+	if err != nil {
+		return err
+	}
+
+	existing, err := clientset.Core.CoreV1().Secrets(g.Namespace).Get(secret.Name, metav1.GetOptions{})
+	switch {
+	case k8serrors.IsNotFound(err):
+		_, err = clientset.Core.CoreV1().Secrets(g.Namespace).Create(secret)
+	case err != nil:
+	case existing.Type != secret.Type:
+		// secret type is immutable, secret has to be recreated
+		if err = clientset.Core.CoreV1().Secrets(g.Namespace).Delete(secret.Name, &metav1.DeleteOptions{}); err == nil {
+			_, err = clientset.Core.CoreV1().Secrets(g.Namespace).Create(secret)
+		}
+	default:
+		if existing.Labels == nil {
+			existing.Labels = make(map[string]string)
+		}
+		existing.Labels[credentialsLabel] = gitType
+		existing.Annotations = secret.Annotations
+		existing.Data = secret.Data
+		_, err = clientset.Core.CoreV1().Secrets(g.Namespace).Update(existing)
 	}
-	secretData := map[string]string{
-		"ssh-privatekey": g.Key,
+	if err != nil {
+		return err
 	}
-	secret := corev1.Secret{
-		Type: "kubernetes.io/ssh-auth",
+
+	serviceAccount := g.ServiceAccount
+	if serviceAccount == "" {
+		serviceAccount = "default"
+	}
+	sa, err := clientset.Core.CoreV1().ServiceAccounts(g.Namespace).Get(serviceAccount, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	for _, v := range sa.Secrets {
+		if v.Name == secret.Name {
+			return nil
+		}
+	}
+	sa.Secrets = append(sa.Secrets, corev1.ObjectReference{
+		Name:      secret.Name,
+		Namespace: g.Namespace,
+	})
+	_, err = clientset.Core.CoreV1().ServiceAccounts(g.Namespace).Update(sa)
+	return err
+}
+
+// secret returns SSH auth secret if HTTPS credentials are not set, otherwise basic auth secret
+func (g *GitCreds) secret() (*corev1.Secret, error) {
+	host := g.Host
+	if host == "" {
+		host = "github.com"
+	}
+	host = strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(host, "https://"), "http://"), "/")
+
+	secret := &corev1.Secret{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "Secret",
 			APIVersion: "v1",
 		},
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      secretName,
+			Name:      g.Name,
 			Namespace: g.Namespace,
-			Annotations: map[string]string{
-				"build.knative.dev/git-0": "*",
-			},
+			Labels:    map[string]string{credentialsLabel: gitType},
 		},
-		StringData: secretData,
+		Data: make(map[string][]byte),
 	}
-	_, err := clientset.Core.CoreV1().Secrets(client.Namespace).Create(&secret)
-	if k8serrors.IsAlreadyExists(err) {
-		oldSecret, err := clientset.Core.CoreV1().Secrets(client.Namespace).Get(secretName, metav1.GetOptions{})
-		if err != nil {
-			return err
+
+	if g.Password != "" || g.Username != "" {
+		if g.Password == "" {
+			return nil, fmt.Errorf("password or token is required for HTTPS credentials")
 		}
-		oldSecret.StringData = secretData
-		if _, err := clientset.Core.CoreV1().Secrets(client.Namespace).Update(oldSecret); err != nil {
-			return err
+		username := g.Username
+		if username == "" {
+			username = defaultGitUsername
 		}
-	} else if err != nil {
-		return err
-	}
-	sa, err := clientset.Core.CoreV1().ServiceAccounts(client.Namespace).Get("default", metav1.GetOptions{})
-	if err != nil {
-		return err
+		if secret.Name == "" {
+			secret.Name = basicAuthSecretName
+		}
+		secret.Type = corev1.SecretTypeBasicAuth
+		secret.Annotations = map[string]string{gitAnnotation: "https://" + host}
+		secret.Data[corev1.BasicAuthUsernameKey] = []byte(username)
+		secret.Data[corev1.BasicAuthPasswordKey] = []byte(g.Password)
+		return secret, nil
 	}
 
-	for _, v := range sa.Secrets {
-		if v.Name == secretName {
-			return nil
+	key := g.Key
+	if key == "" {
+		key = readKey()
+	} else {
+		data, err := ioutil.ReadFile(key)
+		if err != nil {
+			return nil, fmt.Errorf("reading SSH key: %s", err)
 		}
+		key = string(data)
 	}
-	sa.Secrets = append(sa.Secrets, corev1.ObjectReference{
-		Name:      secretName,
-		Namespace: client.Namespace,
-	})
-	_, err = clientset.Core.CoreV1().ServiceAccounts(client.Namespace).Update(sa)
-	return err
+	if strings.TrimSpace(key) == "" {
+		return nil, fmt.Errorf("SSH key is empty")
+	}
+	if secret.Name == "" {
+		secret.Name = secretName
+	}
+	secret.Type = corev1.SecretTypeSSHAuth
+	secret.Annotations = map[string]string{gitAnnotation: host}
+	secret.Data[corev1.SSHAuthPrivateKey] = []byte(key)
+	if g.KnownHosts != "" {
+		data, err := ioutil.ReadFile(g.KnownHosts)
+		if err != nil {
+			return nil, fmt.Errorf("reading known hosts: %s", err)
+		}
+		secret.Data["known_hosts"] = data
+	}
+	return secret, nil
 }
 
-func (g *GitCreds) readStdin() {
+// readKey reads SSH key from stdin
+func readKey() string {
 	var key string
 	fmt.Printf("SSH key:\n")
 	scanner := bufio.NewScanner(os.Stdin)
 	for scanner.Scan() {
-		line := scanner.Text()
-		key = fmt.Sprintf("%s\n%s", key, line)
+		key += scanner.Text() + "\n"
 	}
-	g.Key = key
+	return key
 }
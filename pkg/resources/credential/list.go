@@ -0,0 +1,271 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credential
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/printer"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+const (
+	// credentialsLabel marks secrets created by tm with the type of credentials they contain
+	credentialsLabel = "cli.triggermesh.io/credentials"
+	registryType     = "registry"
+	gitType          = "git"
+	// gitAnnotationPrefix is the prefix of tekton annotations with git hosts
+	gitAnnotationPrefix = "tekton.dev/git-"
+)
+
+// GetTable converts credentials list into printable object
+func (c *Credential) GetTable(list []Credential) printer.Table {
+	table := printer.Table{
+		Headers: []string{
+			"Namespace",
+			"Name",
+			"Type",
+			"Hosts",
+			"Usernames",
+			"Service Accounts",
+			"Age",
+			"Status",
+		},
+		Rows: make([][]string, 0, len(list)),
+	}
+
+	for _, item := range list {
+		table.Rows = append(table.Rows, c.row(&item))
+	}
+	return table
+}
+
+func (c *Credential) row(item *Credential) []string {
+	age := ""
+	if !item.CreationTimestamp.IsZero() {
+		age = duration.HumanDuration(time.Since(item.CreationTimestamp.Time))
+	}
+	status := "Ok"
+	if item.Missing {
+		status = "Missing"
+	}
+	return []string{
+		item.Namespace,
+		item.Name,
+		item.Type,
+		strings.Join(item.Hosts, ","),
+		strings.Join(item.Usernames, ","),
+		strings.Join(item.ServiceAccounts, ","),
+		age,
+		status,
+	}
+}
+
+// GetObject converts credentials summary into printable object
+func (c *Credential) GetObject(credential *Credential) printer.Object {
+	return printer.Object{
+		Fields: map[string]interface{}{
+			"Name":              Credential{}.Name,
+			"Namespace":         Credential{}.Namespace,
+			"Type":              Credential{}.Type,
+			"Hosts":             Credential{}.Hosts,
+			"PushRegistry":      Credential{}.PushRegistry,
+			"Usernames":         Credential{}.Usernames,
+			"ServiceAccounts":   Credential{}.ServiceAccounts,
+			"Missing":           Credential{}.Missing,
+			"CreationTimestamp": metav1.Time{},
+		},
+		K8sObject: credential,
+	}
+}
+
+// List returns registry and git credentials in the namespace along with
+// the secrets that service accounts still reference but that do not exist anymore
+func (c *Credential) List(clientset *client.ConfigSet) ([]Credential, error) {
+	secrets, err := clientset.Core.CoreV1().Secrets(c.Namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	references, err := serviceAccountReferences(clientset, c.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []Credential
+	existing := make(map[string]bool)
+	for i := range secrets.Items {
+		secret := &secrets.Items[i]
+		existing[secret.Name] = true
+		if credentialsType(secret) == "" {
+			continue
+		}
+		credential := summary(secret)
+		credential.ServiceAccounts = references[secret.Name]
+		list = append(list, credential)
+	}
+	for name, accounts := range references {
+		if existing[name] {
+			continue
+		}
+		list = append(list, Credential{
+			Name:            name,
+			Namespace:       c.Namespace,
+			ServiceAccounts: accounts,
+			Missing:         true,
+		})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list, nil
+}
+
+// Get returns summary of the credentials secret
+func (c *Credential) Get(clientset *client.ConfigSet) (*Credential, error) {
+	references, err := serviceAccountReferences(clientset, c.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := clientset.Core.CoreV1().Secrets(c.Namespace).Get(c.Name, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) && len(references[c.Name]) != 0 {
+		return &Credential{
+			Name:            c.Name,
+			Namespace:       c.Namespace,
+			ServiceAccounts: references[c.Name],
+			Missing:         true,
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if credentialsType(secret) == "" {
+		return nil, fmt.Errorf("secret %q does not contain registry or git credentials", c.Name)
+	}
+	credential := summary(secret)
+	credential.ServiceAccounts = references[c.Name]
+	return &credential, nil
+}
+
+// credentialsType returns the type of credentials stored in the secret or empty string
+// if secret was not created by tm. Secrets created before labeling are recognized by their content.
+func credentialsType(secret *corev1.Secret) string {
+	if t, ok := secret.Labels[credentialsLabel]; ok {
+		return t
+	}
+	switch secret.Type {
+	case corev1.SecretTypeDockerConfigJson:
+		if _, ok := secret.Data[pushConfig]; ok {
+			return registryType
+		}
+	case corev1.SecretTypeSSHAuth, corev1.SecretTypeBasicAuth:
+		if len(gitHosts(secret)) != 0 {
+			return gitType
+		}
+	}
+	return ""
+}
+
+// summary returns credentials secret description with masked usernames
+func summary(secret *corev1.Secret) Credential {
+	credential := Credential{
+		Name:              secret.Name,
+		Namespace:         secret.Namespace,
+		Type:              credentialsType(secret),
+		CreationTimestamp: secret.CreationTimestamp,
+	}
+	switch credential.Type {
+	case registryType:
+		auths := make(map[string]registryAuth)
+		for _, key := range []string{pullConfig, pushConfig} {
+			var config dockerConfig
+			if err := json.Unmarshal(secret.Data[key], &config); err != nil {
+				continue
+			}
+			for host, auth := range config.Auths {
+				auths[host] = auth
+			}
+			if key == pushConfig {
+				credential.PushRegistry = config.PushRegistry
+			}
+		}
+		for host := range auths {
+			credential.Hosts = append(credential.Hosts, host)
+		}
+		sort.Strings(credential.Hosts)
+		for _, host := range credential.Hosts {
+			credential.Usernames = append(credential.Usernames, mask(auths[host].Username))
+		}
+	case gitType:
+		credential.Hosts = gitHosts(secret)
+		if username, ok := secret.Data[corev1.BasicAuthUsernameKey]; ok {
+			credential.Usernames = []string{mask(string(username))}
+		}
+	}
+	return credential
+}
+
+// gitHosts returns the hosts from secret tekton git annotations
+func gitHosts(secret *corev1.Secret) []string {
+	var hosts []string
+	for k, v := range secret.Annotations {
+		if strings.HasPrefix(k, gitAnnotationPrefix) {
+			hosts = append(hosts, v)
+		}
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
+// serviceAccountReferences returns the names of service accounts referencing each secret
+// either in image pull secrets or in secrets lists
+func serviceAccountReferences(clientset *client.ConfigSet, namespace string) (map[string][]string, error) {
+	accounts, err := clientset.Core.CoreV1().ServiceAccounts(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	references := make(map[string][]string)
+	for _, sa := range accounts.Items {
+		names := make(map[string]bool)
+		for _, v := range sa.ImagePullSecrets {
+			names[v.Name] = true
+		}
+		for _, v := range sa.Secrets {
+			names[v.Name] = true
+		}
+		for name := range names {
+			references[name] = append(references[name], sa.Name)
+		}
+	}
+	for _, accounts := range references {
+		sort.Strings(accounts)
+	}
+	return references, nil
+}
+
+// mask hides the value leaving only its first and last characters visible
+func mask(value string) string {
+	if len(value) <= 4 {
+		return strings.Repeat("*", len(value))
+	}
+	return value[:2] + "***" + value[len(value)-2:]
+}
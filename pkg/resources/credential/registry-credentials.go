@@ -16,6 +16,7 @@ package credential
 
 import (
 	"bufio"
+	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
@@ -23,65 +24,186 @@ import (
 	"github.com/triggermesh/tm/pkg/client"
 	"golang.org/x/crypto/ssh/terminal"
 	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-// SetRegistryCreds creates Secret with docker registry credentials json which later can be mounted as config.json file
+const (
+	// pullConfig is the secret key of the docker config used to pull images
+	pullConfig = ".dockerconfigjson"
+	// pushConfig is the secret key of the docker config mounted as config.json into the build pods
+	pushConfig = "config.json"
+)
+
+// dockerConfig is the structure of the docker config json stored in the registry secret.
+// PushRegistry is the host of the auths entry that image names are built from.
+type dockerConfig struct {
+	Project      string                  `json:"project,omitempty"`
+	PushRegistry string                  `json:"pushRegistry,omitempty"`
+	Auths        map[string]registryAuth `json:"auths"`
+}
+
+type registryAuth struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// CreateRegistryCreds creates Secret with docker registry credentials json which later can be mounted as config.json file.
+// If docker config path is set, credentials are imported from it, otherwise they are read from the arguments or stdin.
+// In append mode registry host is added to the existing secret instead of replacing its credentials.
 func (c *RegistryCreds) CreateRegistryCreds(clientset *client.ConfigSet) error {
-	secrets := make(map[string]string)
-	if !gitlabCI() && (len(c.Password) == 0 || len(c.Host) == 0 || len(c.Username) == 0) {
-		if err := c.readStdin(); err != nil {
+	auths, err := c.registryAuths()
+	if err != nil {
+		return err
+	}
+
+	data := make(map[string][]byte)
+	secret, err := clientset.Core.CoreV1().Secrets(c.Namespace).Get(c.Name, metav1.GetOptions{})
+	switch {
+	case err == nil:
+		for k, v := range secret.Data {
+			data[k] = v
+		}
+	case k8serrors.IsNotFound(err):
+		secret = nil
+	default:
+		return err
+	}
+
+	if c.Pull || c.Pull == c.Push {
+		if data[pullConfig], err = c.updateConfig(data[pullConfig], auths, false); err != nil {
+			return fmt.Errorf("pull credentials: %s", err)
+		}
+	}
+	if c.Push || c.Push == c.Pull {
+		if data[pushConfig], err = c.updateConfig(data[pushConfig], auths, true); err != nil {
+			return fmt.Errorf("push credentials: %s", err)
+		}
+	}
+	if _, ok := data[pullConfig]; !ok {
+		data[pullConfig] = []byte("{}")
+	}
+
+	if secret != nil && secret.Type != corev1.SecretTypeDockerConfigJson {
+		// secret type is immutable, secret has to be recreated
+		if err := clientset.Core.CoreV1().Secrets(c.Namespace).Delete(c.Name, &metav1.DeleteOptions{}); err != nil {
 			return err
 		}
+		secret = nil
 	}
-	secret := fmt.Sprintf("{\"project\":%q,\"auths\":{%q:{\"username\":%q,\"password\":%q}}}", c.ProjectID, c.Host, c.Username, c.Password)
-	if s, err := clientset.Core.CoreV1().Secrets(c.Namespace).Get(c.Name, metav1.GetOptions{}); err == nil {
-		for k, v := range s.Data {
-			secrets[k] = string(v)
+	if secret != nil {
+		if secret.Labels == nil {
+			secret.Labels = make(map[string]string)
+		}
+		secret.Labels[credentialsLabel] = registryType
+		secret.Data = data
+		if _, err := clientset.Core.CoreV1().Secrets(c.Namespace).Update(secret); err != nil {
+			return err
+		}
+	} else {
+		newSecret := corev1.Secret{
+			Type: "kubernetes.io/dockerconfigjson",
+			TypeMeta: metav1.TypeMeta{
+				Kind:       "Secret",
+				APIVersion: "v1",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      c.Name,
+				Namespace: c.Namespace,
+				Labels:    map[string]string{credentialsLabel: registryType},
+			},
+			Data: data,
 		}
-		if err = clientset.Core.CoreV1().Secrets(c.Namespace).Delete(c.Name, &metav1.DeleteOptions{}); err != nil {
+		if _, err := clientset.Core.CoreV1().Secrets(c.Namespace).Create(&newSecret); err != nil {
 			return err
 		}
 	}
 
 	if c.Pull || c.Pull == c.Push {
-		secrets[".dockerconfigjson"] = secret
+		return c.addPullSecret(clientset)
 	}
-	if c.Push || c.Push == c.Pull {
-		secrets["config.json"] = secret
+	return nil
+}
+
+// addPullSecret appends the secret to the service account image pull secrets
+func (c *RegistryCreds) addPullSecret(clientset *client.ConfigSet) error {
+	serviceAccount := c.ServiceAccount
+	if serviceAccount == "" {
+		serviceAccount = "default"
 	}
-	if _, ok := secrets[".dockerconfigjson"]; !ok {
-		secrets[".dockerconfigjson"] = "{}"
+	sa, err := clientset.Core.CoreV1().ServiceAccounts(c.Namespace).Get(serviceAccount, metav1.GetOptions{})
+	if err != nil {
+		return err
 	}
-	newSecret := corev1.Secret{
-		Type: "kubernetes.io/dockerconfigjson",
-		TypeMeta: metav1.TypeMeta{
-			Kind:       "Secret",
-			APIVersion: "v1",
-		},
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      c.Name,
-			Namespace: client.Namespace,
-		},
-		StringData: secrets,
+	for _, v := range sa.ImagePullSecrets {
+		if v.Name == c.Name {
+			return nil
+		}
 	}
-	if _, err := clientset.Core.CoreV1().Secrets(client.Namespace).Create(&newSecret); err != nil {
-		return err
+	sa.ImagePullSecrets = append(sa.ImagePullSecrets, corev1.LocalObjectReference{Name: c.Name})
+	_, err = clientset.Core.CoreV1().ServiceAccounts(c.Namespace).Update(sa)
+	return err
+}
+
+// registryAuths returns credentials of the registries to store in the secret
+func (c *RegistryCreds) registryAuths() (map[string]registryAuth, error) {
+	if c.FromDockerConfig != "" {
+		return c.dockerConfigAuths(os.Stdin)
+	}
+	if !gitlabCI() && (len(c.Password) == 0 || len(c.Host) == 0 || len(c.Username) == 0) {
+		if err := c.readStdin(); err != nil {
+			return nil, err
+		}
 	}
+	return map[string]registryAuth{
+		c.Host: {
+			Username: c.Username,
+			Password: c.Password,
+		},
+	}, nil
+}
 
-	if c.Pull || c.Pull == c.Push {
-		sa, err := clientset.Core.CoreV1().ServiceAccounts(client.Namespace).Get("default", metav1.GetOptions{})
-		if err != nil {
-			return err
+// updateConfig adds registries credentials to the docker config json.
+// Existing registries are kept only in append mode.
+func (c *RegistryCreds) updateConfig(data []byte, auths map[string]registryAuth, push bool) ([]byte, error) {
+	config := dockerConfig{Auths: make(map[string]registryAuth)}
+	if c.Append && len(data) != 0 {
+		if err := json.Unmarshal(data, &config); err != nil {
+			return nil, fmt.Errorf("parsing existing secret: %s", err)
 		}
-		sa.ImagePullSecrets = []corev1.LocalObjectReference{
-			{Name: c.Name},
+		if config.Auths == nil {
+			config.Auths = make(map[string]registryAuth)
 		}
-		if _, err := clientset.Core.CoreV1().ServiceAccounts(client.Namespace).Update(sa); err != nil {
-			return err
+		// secrets created before multiple registries support have the only push registry
+		if config.PushRegistry == "" && len(config.Auths) == 1 {
+			for host := range config.Auths {
+				config.PushRegistry = host
+			}
 		}
 	}
-	return nil
+	for host, auth := range auths {
+		config.Auths[host] = auth
+	}
+	if c.ProjectID != "" {
+		config.Project = c.ProjectID
+	}
+	if push {
+		switch {
+		case c.PushRegistry != "":
+			config.PushRegistry = c.PushRegistry
+		case config.PushRegistry != "":
+		case len(auths) == 1:
+			for host := range auths {
+				config.PushRegistry = host
+			}
+		default:
+			return nil, fmt.Errorf("multiple registries are added, push registry must be set")
+		}
+		if _, ok := config.Auths[config.PushRegistry]; !ok {
+			return nil, fmt.Errorf("push registry %q has no credentials", config.PushRegistry)
+		}
+	}
+	return json.Marshal(config)
 }
 
 func (c *RegistryCreds) readStdin() error {
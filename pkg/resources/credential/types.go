@@ -14,10 +14,23 @@
 
 package credential
 
+import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+// GitCreds contains git SSH key or HTTPS credentials for the host
 type GitCreds struct {
+	Name      string
 	Namespace string
-	// Host string
+	// Host is git server host the credentials are used for
+	Host string
+	// Key is SSH private key file path
 	Key string
+	// KnownHosts is SSH known_hosts file path
+	KnownHosts string
+	Username   string
+	// Password is HTTPS password or access token
+	Password string
+	// ServiceAccount is the name of service account that credentials are attached to
+	ServiceAccount string
 }
 
 // RegistryCreds contains docker registry credentials
@@ -30,4 +43,29 @@ type RegistryCreds struct {
 	Password  string
 	Pull      bool
 	Push      bool
+	// Append adds registry to the existing secret instead of replacing it
+	Append bool
+	// PushRegistry is the registry host used in the built image names
+	PushRegistry string
+	// FromDockerConfig is the path of local docker config to import credentials from
+	FromDockerConfig string
+	// ServiceAccount is the name of service account that pull credentials are attached to
+	ServiceAccount string
+}
+
+// Credential is the summary of registry or git credentials secret created by tm.
+// Usernames are masked, secret values are never included.
+type Credential struct {
+	Name      string   `json:"name"`
+	Namespace string   `json:"namespace"`
+	Type      string   `json:"type"`
+	Hosts     []string `json:"hosts,omitempty"`
+	// PushRegistry is the registry host used in the built image names
+	PushRegistry string   `json:"pushRegistry,omitempty"`
+	Usernames    []string `json:"usernames,omitempty"`
+	// ServiceAccounts are the names of service accounts that reference the secret
+	ServiceAccounts []string `json:"serviceAccounts,omitempty"`
+	// Missing is set if the secret is referenced by service accounts but does not exist
+	Missing           bool        `json:"missing,omitempty"`
+	CreationTimestamp metav1.Time `json:"creationTimestamp,omitempty"`
 }
@@ -0,0 +1,120 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package event
+
+import (
+	"sort"
+	"time"
+
+	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/printer"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+// Event represents k8s events related to a set of objects
+type Event struct {
+	Namespace string
+}
+
+// List returns deduplicated events of the given objects sorted by time.
+// Events are requested per object with the involved object field selector.
+func (e *Event) List(clientset *client.ConfigSet, objects ...metav1.Object) ([]corev1.Event, error) {
+	listed := make(map[types.UID]bool, len(objects))
+	var events []corev1.Event
+	for _, object := range objects {
+		if listed[object.GetUID()] {
+			continue
+		}
+		listed[object.GetUID()] = true
+		list, err := clientset.Core.CoreV1().Events(e.Namespace).List(metav1.ListOptions{
+			FieldSelector: fields.Set{
+				"involvedObject.name": object.GetName(),
+				"involvedObject.uid":  string(object.GetUID()),
+			}.String(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, list.Items...)
+	}
+	return dedup(events), nil
+}
+
+// dedup merges events with the same object, reason and message
+// and sorts the result by the last occurrence time
+func dedup(events []corev1.Event) []corev1.Event {
+	type key struct {
+		kind, name, reason, message string
+	}
+	index := make(map[key]int)
+	var result []corev1.Event
+	for _, event := range events {
+		k := key{event.InvolvedObject.Kind, event.InvolvedObject.Name, event.Reason, event.Message}
+		i, ok := index[k]
+		if !ok {
+			index[k] = len(result)
+			result = append(result, event)
+			continue
+		}
+		result[i].Count += event.Count
+		if lastSeen(event).After(lastSeen(result[i])) {
+			result[i].LastTimestamp = event.LastTimestamp
+			result[i].EventTime = event.EventTime
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return lastSeen(result[i]).Before(lastSeen(result[j]))
+	})
+	return result
+}
+
+func lastSeen(event corev1.Event) time.Time {
+	switch {
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	default:
+		return event.FirstTimestamp.Time
+	}
+}
+
+// GetTable converts events into printable object
+func (e *Event) GetTable(events []corev1.Event) printer.Table {
+	table := printer.Table{
+		Headers: []string{
+			"Last Seen",
+			"Type",
+			"Reason",
+			"Object",
+			"Message",
+		},
+		Rows: make([][]string, 0, len(events)),
+	}
+	for _, event := range events {
+		table.Rows = append(table.Rows, []string{
+			duration.HumanDuration(time.Since(lastSeen(event))),
+			event.Type,
+			event.Reason,
+			event.InvolvedObject.Kind + "/" + event.InvolvedObject.Name,
+			event.Message,
+		})
+	}
+	return table
+}
@@ -0,0 +1,107 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package event
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/triggermesh/tm/pkg/client/fake"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	k8sFake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestDedup(t *testing.T) {
+	now := time.Now()
+	newEvent := func(pod, reason string, count int32, ago time.Duration) corev1.Event {
+		return corev1.Event{
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: pod},
+			Reason:         reason,
+			Message:        reason + " message",
+			Count:          count,
+			LastTimestamp:  metav1.NewTime(now.Add(-ago)),
+		}
+	}
+
+	events := dedup([]corev1.Event{
+		newEvent("foo", "BackOff", 2, time.Minute),
+		newEvent("foo", "Scheduled", 1, time.Hour),
+		newEvent("bar", "BackOff", 1, 2*time.Minute),
+		newEvent("foo", "BackOff", 3, time.Second),
+	})
+
+	assert.Len(t, events, 3)
+	assert.Equal(t, "Scheduled", events[0].Reason)
+	assert.Equal(t, "bar", events[1].InvolvedObject.Name)
+	assert.Equal(t, "foo", events[2].InvolvedObject.Name)
+	assert.Equal(t, int32(5), events[2].Count)
+	assert.Equal(t, now.Add(-time.Second).Unix(), events[2].LastTimestamp.Unix())
+}
+
+func TestFakeList(t *testing.T) {
+	newEvent := func(name, pod string, uid types.UID) *corev1.Event {
+		return &corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: fake.Namespace},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: pod, UID: uid},
+			Reason:         name,
+		}
+	}
+	events := []*corev1.Event{
+		newEvent("foo-scheduled", "foo", "foo-uid"),
+		newEvent("foo-old-pulled", "foo", "foo-old-uid"),
+		newEvent("bar-scheduled", "bar", "bar-uid"),
+		newEvent("baz-scheduled", "baz", "baz-uid"),
+	}
+	clientset := fake.NewConfigSet()
+	// generated fake clientset ignores field selectors
+	var selectors []string
+	clientset.Core.(*k8sFake.Clientset).PrependReactor("list", "events", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		restrictions := action.(k8stesting.ListAction).GetListRestrictions()
+		selectors = append(selectors, restrictions.Fields.String())
+		list := &corev1.EventList{}
+		for _, event := range events {
+			if restrictions.Fields.Matches(fields.Set{
+				"involvedObject.name": event.InvolvedObject.Name,
+				"involvedObject.uid":  string(event.InvolvedObject.UID),
+			}) {
+				list.Items = append(list.Items, *event)
+			}
+		}
+		return true, list, nil
+	})
+
+	foo := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo", UID: "foo-uid"}}
+	bar := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "bar", UID: "bar-uid"}}
+	e := &Event{Namespace: fake.Namespace}
+	list, err := e.List(clientset, foo, bar, foo)
+	require.NoError(t, err)
+
+	var reasons []string
+	for _, event := range list {
+		reasons = append(reasons, event.Reason)
+	}
+	assert.ElementsMatch(t, []string{"foo-scheduled", "bar-scheduled"}, reasons)
+	assert.Equal(t, []string{
+		"involvedObject.name=foo,involvedObject.uid=foo-uid",
+		"involvedObject.name=bar,involvedObject.uid=bar-uid",
+	}, selectors)
+}
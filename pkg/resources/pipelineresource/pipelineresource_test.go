@@ -15,11 +15,13 @@
 package pipelineresource
 
 import (
-	"os"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
-	"github.com/triggermesh/tm/pkg/client"
+	v1alpha1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"github.com/triggermesh/tm/pkg/client/fake"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // func TestCreate(t *testing.T) {
@@ -49,42 +51,35 @@ import (
 // }
 
 func TestList(t *testing.T) {
-	namespace := "test-namespace"
-	if ns, ok := os.LookupEnv("NAMESPACE"); ok {
-		namespace = ns
-	}
-	testClient, err := client.NewClient(client.ConfigPath(""))
-	assert.NoError(t, err)
+	testClient := fake.NewConfigSet(&v1alpha1.PipelineResource{
+		ObjectMeta: metav1.ObjectMeta{Name: "bar", Namespace: fake.Namespace},
+	})
 
-	pipeline := &PipelineResource{Name: "Foo", Namespace: namespace}
+	pipeline := &PipelineResource{Name: "Foo", Namespace: fake.Namespace}
 
-	_, err = pipeline.List(&testClient)
+	list, err := pipeline.List(testClient)
 	assert.NoError(t, err)
+	assert.Len(t, list.Items, 1)
 }
 
 func TestGet(t *testing.T) {
-	namespace := "test-namespace"
-	if ns, ok := os.LookupEnv("NAMESPACE"); ok {
-		namespace = ns
-	}
-	testClient, err := client.NewClient(client.ConfigPath(""))
-	assert.NoError(t, err)
+	testClient := fake.NewConfigSet()
 
-	pipeline := &PipelineResource{Name: "Foo", Namespace: namespace}
-	result, err := pipeline.Get(&testClient)
-	assert.Error(t, err)
-	assert.Equal(t, "", result.Name)
+	pipeline := &PipelineResource{Name: "Foo", Namespace: fake.Namespace}
+	_, err := pipeline.Get(testClient)
+	assert.True(t, k8serrors.IsNotFound(err))
 }
 
 func TestDelete(t *testing.T) {
-	namespace := "test-namespace"
-	if ns, ok := os.LookupEnv("NAMESPACE"); ok {
-		namespace = ns
-	}
-	testClient, err := client.NewClient(client.ConfigPath(""))
-	assert.NoError(t, err)
+	testClient := fake.NewConfigSet(&v1alpha1.PipelineResource{
+		ObjectMeta: metav1.ObjectMeta{Name: "bar", Namespace: fake.Namespace},
+	})
 
-	pipeline := &PipelineResource{Name: "Foo", Namespace: namespace}
-	err = pipeline.Delete(&testClient)
+	pipeline := &PipelineResource{Name: "Foo", Namespace: fake.Namespace}
+	err := pipeline.Delete(testClient)
 	assert.Error(t, err)
+
+	pipeline.Name = "bar"
+	err = pipeline.Delete(testClient)
+	assert.NoError(t, err)
 }
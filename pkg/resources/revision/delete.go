@@ -21,5 +21,5 @@ import (
 
 // Revision remove knative revision object
 func (r *Revision) Delete(clientset *client.ConfigSet) error {
-	return clientset.Serving.ServingV1alpha1().Revisions(r.Namespace).Delete(r.Name, &metav1.DeleteOptions{})
+	return clientset.Serving.ServingV1().Revisions(r.Namespace).Delete(r.Name, &metav1.DeleteOptions{})
 }
@@ -0,0 +1,159 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package revision
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/triggermesh/tm/pkg/client/fake"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	k8sFake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
+)
+
+func TestFakeListPodsDelete(t *testing.T) {
+	newRevision := func(name, service string) *servingv1.Revision {
+		return &servingv1.Revision{ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: fake.Namespace,
+			Labels:    map[string]string{serviceLabelKey: service},
+		}}
+	}
+	newPod := func(name, revision string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: fake.Namespace,
+			Labels:    map[string]string{revisionLabelKey: revision},
+		}}
+	}
+	clientset := fake.NewConfigSet(
+		newRevision("foo-00001", "foo"),
+		newRevision("foo-00002", "foo"),
+		newRevision("bar-00001", "bar"),
+		newPod("foo-00001-pod-1", "foo-00001"),
+		newPod("foo-00001-pod-2", "foo-00001"),
+		newPod("foo-00002-pod-1", "foo-00002"),
+	)
+
+	r := &Revision{Name: "foo-00001", Namespace: fake.Namespace, Service: "foo"}
+	list, err := r.List(clientset)
+	require.NoError(t, err)
+	assert.Len(t, list.Items, 2)
+
+	pods, err := r.Pods(clientset)
+	require.NoError(t, err)
+	assert.Len(t, pods.Items, 2)
+
+	require.NoError(t, r.Delete(clientset))
+	_, err = r.Get(clientset)
+	assert.Error(t, err)
+}
+
+func TestFakeLogs(t *testing.T) {
+	newPod := func(name, revision string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: fake.Namespace,
+				Labels:    map[string]string{revisionLabelKey: revision},
+			},
+			Status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name:  userContainer,
+					State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+				}},
+			},
+		}
+	}
+	clientset := fake.NewConfigSet(
+		newPod("foo-00001-pod-1", "foo-00001"),
+		newPod("foo-00001-pod-2", "foo-00001"),
+		newPod("foo-00002-pod-1", "foo-00002"),
+	)
+	fake.ScriptLogs(clientset, map[string]string{
+		"foo-00001-pod-1/user-container": "hello\n",
+		"foo-00001-pod-2/user-container": "world\n",
+		"foo-00001-pod-1/queue-proxy":    "proxy\n",
+	})
+
+	var output bytes.Buffer
+	r := &Revision{Name: "foo-00001", Namespace: fake.Namespace}
+	require.NoError(t, r.Logs(clientset, &corev1.PodLogOptions{}, &output))
+	assert.ElementsMatch(t, []string{
+		"[foo-00001-pod-1] hello",
+		"[foo-00001-pod-2] world",
+	}, strings.Split(strings.TrimSpace(output.String()), "\n"))
+
+	r = &Revision{Name: "foo-00002", Namespace: fake.Namespace}
+	assert.Error(t, r.Logs(clientset, &corev1.PodLogOptions{}, &bytes.Buffer{}))
+
+	// revision scaled to zero has no pods to print logs of
+	output.Reset()
+	r = &Revision{Name: "foo-00003", Namespace: fake.Namespace}
+	assert.NoError(t, r.Logs(clientset, &corev1.PodLogOptions{}, &output))
+	assert.Empty(t, output.String())
+}
+
+func TestFakeFollowLogs(t *testing.T) {
+	pod := func(name string, started bool) *corev1.Pod {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: fake.Namespace,
+			Labels:    map[string]string{revisionLabelKey: "foo-00001"},
+		}}
+		if started {
+			pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+				Name:  userContainer,
+				State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+			}}
+		}
+		return pod
+	}
+	// revision is scaled to zero, pods appear after the logs are requested
+	clientset := fake.NewConfigSet()
+	events := []watch.Event{
+		{Type: watch.Added, Object: pod("foo-00001-pod-1", false)},
+		{Type: watch.Modified, Object: pod("foo-00001-pod-1", true)},
+		{Type: watch.Modified, Object: pod("foo-00001-pod-1", true)},
+		{Type: watch.Added, Object: pod("foo-00001-pod-2", true)},
+	}
+	clientset.Core.(*k8sFake.Clientset).PrependWatchReactor("pods", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		w := watch.NewFakeWithChanSize(len(events), false)
+		for _, event := range events {
+			w.Action(event.Type, event.Object)
+		}
+		w.Stop()
+		return true, w, nil
+	})
+	fake.ScriptLogs(clientset, map[string]string{
+		"foo-00001-pod-1/user-container": "hello\n",
+		"foo-00001-pod-2/user-container": "world\n",
+	})
+
+	var output bytes.Buffer
+	r := &Revision{Name: "foo-00001", Namespace: fake.Namespace}
+	require.NoError(t, r.Logs(clientset, &corev1.PodLogOptions{Follow: true}, &output))
+	assert.ElementsMatch(t, []string{
+		"[foo-00001-pod-1] hello",
+		"[foo-00001-pod-2] world",
+	}, strings.Split(strings.TrimSpace(output.String()), "\n"))
+}
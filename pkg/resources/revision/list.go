@@ -25,6 +25,8 @@ import (
 	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
 )
 
+const serviceLabelKey = "serving.knative.dev/service"
+
 // GetTable converts k8s list instance into printable object
 func (r *Revision) GetTable(list *servingv1.RevisionList) printer.Table {
 	table := printer.Table{
@@ -74,5 +76,11 @@ func (r *Revision) row(item *servingv1.Revision) []string {
 
 // List returns k8s list object
 func (r *Revision) List(clientset *client.ConfigSet) (*servingv1.RevisionList, error) {
-	return clientset.Serving.ServingV1().Revisions(r.Namespace).List(metav1.ListOptions{})
+	var selector string
+	if r.Service != "" {
+		selector = serviceLabelKey + "=" + r.Service
+	}
+	return clientset.Serving.ServingV1().Revisions(r.Namespace).List(metav1.ListOptions{
+		LabelSelector: selector,
+	})
 }
@@ -0,0 +1,155 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package revision
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/triggermesh/tm/pkg/client"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+const (
+	revisionLabelKey = "serving.knative.dev/revision"
+	userContainer    = "user-container"
+)
+
+// Logs writes user container logs of all revision pods to the output.
+// Each line is prefixed with the pod name. In follow mode revision pods
+// are watched and logs of the new pods are streamed once they are started,
+// so revision that is scaled to zero is waited for instead of failing.
+func (r *Revision) Logs(clientset *client.ConfigSet, options *corev1.PodLogOptions, output io.Writer) error {
+	if options.Follow {
+		return r.followLogs(clientset, options, output)
+	}
+	pods, err := r.Pods(clientset)
+	if err != nil {
+		return err
+	}
+	if len(pods.Items) == 0 {
+		clientset.Log.Infof("Revision %q has no running pods", r.Name)
+		return nil
+	}
+
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	errs := make(chan error, len(pods.Items))
+	for _, pod := range pods.Items {
+		wg.Add(1)
+		go func(pod string) {
+			defer wg.Done()
+			if err := r.copyLogs(clientset, pod, options, output, &mutex); err != nil {
+				errs <- fmt.Errorf("pod %q logs: %s", pod, err)
+			}
+		}(pod.Name)
+	}
+	wg.Wait()
+	close(errs)
+	return <-errs
+}
+
+// followLogs streams logs of the running revision pods and of the pods
+// that are started later until the pods watch is closed
+func (r *Revision) followLogs(clientset *client.ConfigSet, options *corev1.PodLogOptions, output io.Writer) error {
+	pods, err := r.Pods(clientset)
+	if err != nil {
+		return err
+	}
+	watcher, err := clientset.Core.CoreV1().Pods(r.Namespace).Watch(metav1.ListOptions{
+		LabelSelector:   revisionLabelKey + "=" + r.Name,
+		ResourceVersion: pods.ResourceVersion,
+	})
+	if err != nil {
+		return err
+	}
+	defer watcher.Stop()
+
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	streaming := make(map[string]bool)
+	stream := func(pod *corev1.Pod) {
+		if streaming[pod.Name] || !containerStarted(pod, userContainer) {
+			return
+		}
+		streaming[pod.Name] = true
+		wg.Add(1)
+		go func(pod string) {
+			defer wg.Done()
+			if err := r.copyLogs(clientset, pod, options, output, &mutex); err != nil {
+				clientset.Log.Warnf("Pod %q logs streaming interrupted: %s", pod, err)
+			}
+		}(pod.Name)
+	}
+
+	for i := range pods.Items {
+		stream(&pods.Items[i])
+	}
+	if len(streaming) == 0 {
+		clientset.Log.Infof("Waiting for revision %q pods", r.Name)
+	}
+	for event := range watcher.ResultChan() {
+		pod, ok := event.Object.(*corev1.Pod)
+		if !ok {
+			continue
+		}
+		switch event.Type {
+		case watch.Added, watch.Modified:
+			stream(pod)
+		case watch.Deleted:
+			delete(streaming, pod.Name)
+		}
+	}
+	wg.Wait()
+	return nil
+}
+
+// Pods returns the list of revision pods
+func (r *Revision) Pods(clientset *client.ConfigSet) (*corev1.PodList, error) {
+	return clientset.Core.CoreV1().Pods(r.Namespace).List(metav1.ListOptions{
+		LabelSelector: revisionLabelKey + "=" + r.Name,
+	})
+}
+
+func (r *Revision) copyLogs(clientset *client.ConfigSet, pod string, options *corev1.PodLogOptions, output io.Writer, mutex *sync.Mutex) error {
+	opts := options.DeepCopy()
+	opts.Container = userContainer
+	stream, err := clientset.Core.CoreV1().Pods(r.Namespace).GetLogs(pod, opts).Stream()
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	scanner := bufio.NewScanner(stream)
+	for scanner.Scan() {
+		mutex.Lock()
+		fmt.Fprintf(output, "[%s] %s\n", pod, scanner.Text())
+		mutex.Unlock()
+	}
+	return scanner.Err()
+}
+
+func containerStarted(pod *corev1.Pod, container string) bool {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == container {
+			return status.State.Running != nil || status.State.Terminated != nil
+		}
+	}
+	return false
+}
@@ -15,53 +15,45 @@
 package revision
 
 import (
-	"os"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
-	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/client/fake"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
 )
 
 func TestList(t *testing.T) {
+	testClient := fake.NewConfigSet(&servingv1.Revision{
+		ObjectMeta: metav1.ObjectMeta{Name: "bar", Namespace: fake.Namespace},
+	})
 
-	namespace := "test-namespace"
-	if ns, ok := os.LookupEnv("NAMESPACE"); ok {
-		namespace = ns
-	}
-	revisionClient, err := client.NewClient(client.ConfigPath(""))
-	assert.NoError(t, err)
-
-	revision := &Revision{Name: "Foo", Namespace: namespace}
+	revision := &Revision{Name: "Foo", Namespace: fake.Namespace}
 
-	_, err = revision.List(&revisionClient)
+	list, err := revision.List(testClient)
 	assert.NoError(t, err)
+	assert.Len(t, list.Items, 1)
 }
 
 func TestGet(t *testing.T) {
+	testClient := fake.NewConfigSet()
 
-	namespace := "test-namespace"
-	if ns, ok := os.LookupEnv("NAMESPACE"); ok {
-		namespace = ns
-	}
-	revisionClient, err := client.NewClient(client.ConfigPath(""))
-	assert.NoError(t, err)
-
-	revision := &Revision{Name: "Foo", Namespace: namespace}
-	result, err := revision.Get(&revisionClient)
-	assert.Error(t, err)
-	assert.Equal(t, "", result.Name)
+	revision := &Revision{Name: "Foo", Namespace: fake.Namespace}
+	_, err := revision.Get(testClient)
+	assert.True(t, k8serrors.IsNotFound(err))
 }
 
 func TestDelete(t *testing.T) {
+	testClient := fake.NewConfigSet(&servingv1.Revision{
+		ObjectMeta: metav1.ObjectMeta{Name: "bar", Namespace: fake.Namespace},
+	})
 
-	namespace := "test-namespace"
-	if ns, ok := os.LookupEnv("NAMESPACE"); ok {
-		namespace = ns
-	}
-	revisionClient, err := client.NewClient(client.ConfigPath(""))
-	assert.NoError(t, err)
-
-	revision := &Revision{Name: "Foo", Namespace: namespace}
-	err = revision.Delete(&revisionClient)
+	revision := &Revision{Name: "Foo", Namespace: fake.Namespace}
+	err := revision.Delete(testClient)
 	assert.Error(t, err)
+
+	revision.Name = "bar"
+	err = revision.Delete(testClient)
+	assert.NoError(t, err)
 }
@@ -14,7 +14,10 @@
 
 package revision
 
+// Revision represents knative revision object.
+// If Service is set, revision list is limited by the revisions of that service.
 type Revision struct {
 	Name      string
 	Namespace string
+	Service   string
 }
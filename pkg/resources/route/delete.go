@@ -21,5 +21,5 @@ import (
 
 // Route removes knative route object
 func (r *Route) Delete(clientset *client.ConfigSet) error {
-	return clientset.Serving.ServingV1alpha1().Routes(r.Namespace).Delete(r.Name, &metav1.DeleteOptions{})
+	return clientset.Serving.ServingV1().Routes(r.Namespace).Delete(r.Name, &metav1.DeleteOptions{})
 }
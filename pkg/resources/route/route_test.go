@@ -15,50 +15,45 @@
 package route
 
 import (
-	"os"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
-	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/client/fake"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
 )
 
 func TestList(t *testing.T) {
-	namespace := "test-namespace"
-	if ns, ok := os.LookupEnv("NAMESPACE"); ok {
-		namespace = ns
-	}
-	routeClient, err := client.NewClient(client.ConfigPath(""))
-	assert.NoError(t, err)
+	testClient := fake.NewConfigSet(&servingv1.Route{
+		ObjectMeta: metav1.ObjectMeta{Name: "bar", Namespace: fake.Namespace},
+	})
 
-	r := &Route{Name: "Foo", Namespace: namespace}
+	r := &Route{Name: "Foo", Namespace: fake.Namespace}
 
-	_, err = r.List(&routeClient)
+	list, err := r.List(testClient)
 	assert.NoError(t, err)
+	assert.Len(t, list.Items, 1)
 }
 
 func TestGet(t *testing.T) {
-	namespace := "test-namespace"
-	if ns, ok := os.LookupEnv("NAMESPACE"); ok {
-		namespace = ns
-	}
-	routeClient, err := client.NewClient(client.ConfigPath(""))
-	assert.NoError(t, err)
+	testClient := fake.NewConfigSet()
 
-	r := &Route{Name: "Foo", Namespace: namespace}
-	result, err := r.Get(&routeClient)
-	assert.Error(t, err)
-	assert.Equal(t, "", result.Name)
+	r := &Route{Name: "Foo", Namespace: fake.Namespace}
+	_, err := r.Get(testClient)
+	assert.True(t, k8serrors.IsNotFound(err))
 }
 
 func TestDelete(t *testing.T) {
-	namespace := "test-namespace"
-	if ns, ok := os.LookupEnv("NAMESPACE"); ok {
-		namespace = ns
-	}
-	routeClient, err := client.NewClient(client.ConfigPath(""))
-	assert.NoError(t, err)
+	testClient := fake.NewConfigSet(&servingv1.Route{
+		ObjectMeta: metav1.ObjectMeta{Name: "bar", Namespace: fake.Namespace},
+	})
 
-	r := &Route{Name: "Foo", Namespace: namespace}
-	err = r.Delete(&routeClient)
+	r := &Route{Name: "Foo", Namespace: fake.Namespace}
+	err := r.Delete(testClient)
 	assert.Error(t, err)
+
+	r.Name = "bar"
+	err = r.Delete(testClient)
+	assert.NoError(t, err)
 }
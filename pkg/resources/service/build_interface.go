@@ -29,6 +29,7 @@ type Builder interface {
 	Deploy(clientset *client.ConfigSet) (string, error)
 	SetOwner(clientset *client.ConfigSet, owner metav1.OwnerReference) error
 	Delete(clientset *client.ConfigSet) error
+	ImageDigest() string
 }
 
 // NewBuilder checks Service build method (tekton task)
@@ -57,10 +58,15 @@ func NewBuilder(clientset *client.ConfigSet, s *Service) Builder {
 }
 
 func (s *Service) taskRun() *taskrun.TaskRun {
+	serviceAccount := s.BuildServiceAccount
+	if serviceAccount == "" {
+		serviceAccount = s.ServiceAccount
+	}
 	return &taskrun.TaskRun{
-		Name:      s.Name,
-		Namespace: s.Namespace,
-		Params:    s.BuildArgs,
+		Name:           s.Name,
+		Namespace:      s.Namespace,
+		Params:         s.BuildArgs,
+		ServiceAccount: serviceAccount,
 		Function: taskrun.Source{
 			Path:     s.Source,
 			Revision: s.Revision,
@@ -68,7 +74,9 @@ func (s *Service) taskRun() *taskrun.TaskRun {
 		Task: taskrun.Resource{
 			Name: s.Runtime,
 		},
-		Timeout: s.BuildTimeout,
-		Wait:    true,
+		Timeout:    s.BuildTimeout,
+		Quiet:      s.QuietBuild,
+		ForceBuild: s.ForceBuild,
+		Wait:       true,
 	}
 }
@@ -19,6 +19,7 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"sort"
 	"time"
 
 	"github.com/ghodss/yaml"
@@ -30,6 +31,7 @@ import (
 	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
 
 	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/registry"
 )
 
 // time duration to wait for knative service ready state
@@ -37,14 +39,13 @@ const ksvcWaitTimeout = 10 * time.Minute
 
 // Deploy receives Service structure and generate knative/service object to deploy it in knative cluster
 func (s *Service) Deploy(clientset *client.ConfigSet) (string, error) {
-	var err error
-	service := &servingv1.Service{
-		TypeMeta: metav1.TypeMeta{
-			Kind:       "Service",
-			APIVersion: "serving.knative.dev/v1",
-		},
+	service := &servingv1.Service{}
+
+	if err := s.validate(); err != nil {
+		return "", err
 	}
 
+	var err error
 	image := s.Source
 	builder := NewBuilder(clientset, s)
 
@@ -74,50 +75,23 @@ func (s *Service) Deploy(clientset *client.ConfigSet) (string, error) {
 		return fmt.Sprintf("Build-only flag set, service image is %s", image), nil
 	}
 
-	concurrency := int64(s.Concurrency)
-	configuration := servingv1.ConfigurationSpec{
-		Template: servingv1.RevisionTemplateSpec{
-			Spec: servingv1.RevisionSpec{
-				ContainerConcurrency: &concurrency,
-				PodSpec: corev1.PodSpec{
-					Containers: []corev1.Container{
-						{Image: image},
-					},
-				},
-			},
-		},
-	}
-
-	configuration.Template.ObjectMeta = metav1.ObjectMeta{
-		CreationTimestamp: metav1.Time{Time: time.Now()},
-		Annotations:       s.Annotations,
-		Labels:            mapFromSlice(s.Labels),
+	if !s.NoDigestResolve && !client.Dry {
+		image = s.pinImage(image, builder, clientset)
 	}
 
-	configuration.Template.ObjectMeta.GenerateName = s.Name + "-"
-	configuration.Template.ObjectMeta.Namespace = s.Namespace
-	configuration.Template.Spec.PodSpec.Containers[0].Env = s.setupEnv()
-	configuration.Template.Spec.PodSpec.Containers[0].EnvFrom = s.setupEnvSecrets()
-	configuration.Template.Spec.PodSpec.Containers[0].ImagePullPolicy = corev1.PullPolicy(s.PullPolicy)
+	service = s.knativeService(image)
 
-	service.ObjectMeta = metav1.ObjectMeta{
-		Name:              s.Name,
-		Namespace:         s.Namespace,
-		Labels:            configuration.Template.ObjectMeta.Labels,
-		CreationTimestamp: metav1.Time{Time: time.Now()},
-	}
-	service.Spec = servingv1.ServiceSpec{
-		ConfigurationSpec: configuration,
+	if client.Dry {
+		return dryRunOutput(service)
 	}
 
-	if client.Dry {
-		var obj []byte
-		if client.Output == "yaml" {
-			obj, err = yaml.Marshal(service)
+	var stable string
+	if len(s.Canary.Steps) != 0 {
+		if stable = s.stableRevision(clientset); stable != "" {
+			service.Spec.Traffic = canaryTargets(stable, s.Canary.Steps[0])
 		} else {
-			obj, err = json.MarshalIndent(service, "", " ")
+			clientset.Log.Infof("Service %q has no ready revisions, skipping canary rollout", s.Name)
 		}
-		return string(obj), err
 	}
 
 	if service, err = s.createOrUpdate(service, clientset); err != nil {
@@ -139,6 +113,24 @@ func (s *Service) Deploy(clientset *client.ConfigSet) (string, error) {
 		}
 	}
 
+	// triggers, subscriptions and event sources are recreated the same way as PingSources
+	s.syncTriggers(service, clientset)
+	s.syncSubscriptions(service, clientset)
+	s.syncSources(service, clientset)
+
+	if stable != "" && !client.Wait {
+		// rollout blocks for all canary intervals, without --wait
+		// service is left with the first traffic split only
+		clientset.Log.Infof("Routing %d%% of %q traffic to the latest revision, use --wait flag to perform the rest of canary rollout", s.Canary.Steps[0], s.Name)
+	} else if stable != "" {
+		clientset.Log.Infof("Starting canary rollout of %q", s.Name)
+		domain, err := s.rollout(stable, clientset)
+		if err != nil {
+			return "", fmt.Errorf("canary rollout: %s", err)
+		}
+		return fmt.Sprintf("Service %s URL: %s", s.Name, domain), nil
+	}
+
 	if !client.Wait {
 		return fmt.Sprintf("Deployment started. Run \"tm -n %s describe service %s\" to see details", s.Namespace, s.Name), nil
 	}
@@ -148,11 +140,130 @@ func (s *Service) Deploy(clientset *client.ConfigSet) (string, error) {
 	return fmt.Sprintf("Service %s URL: %s", s.Name, domain), err
 }
 
+// pinImage replaces image tag with the digest reported by the builder or
+// stored in the registry, so that the tag update doesn't change the running code
+func (s *Service) pinImage(image string, builder Builder, clientset *client.ConfigSet) string {
+	if registry.IsPinned(image) {
+		return image
+	}
+	var digest string
+	if builder != nil {
+		digest = builder.ImageDigest()
+	}
+	if digest == "" {
+		var err error
+		if digest, err = registry.Digest(clientset, s.Namespace, image); err != nil {
+			clientset.Log.Warnf("Cannot resolve %q digest, deploying by tag: %v", image, err)
+			return image
+		}
+	}
+	pinned, err := registry.Pin(image, digest)
+	if err != nil {
+		clientset.Log.Warnf("Cannot pin %q digest, deploying by tag: %v", image, err)
+		return image
+	}
+	clientset.Log.Infof("Image %s resolved to %s", image, pinned)
+	return pinned
+}
+
+// validate verifies Service parameters that are not checked by the cluster
+// before the possibly long image build
+func (s *Service) validate() error {
+	if _, err := s.trafficTargets(); err != nil {
+		return fmt.Errorf("traffic split: %s", err)
+	}
+	if err := s.validateCanary(); err != nil {
+		return err
+	}
+	if _, err := s.resourceRequirements(); err != nil {
+		return fmt.Errorf("resources: %s", err)
+	}
+	if _, err := s.scalingAnnotations(); err != nil {
+		return fmt.Errorf("scaling: %s", err)
+	}
+	if err := s.validateSources(); err != nil {
+		return fmt.Errorf("event sources: %s", err)
+	}
+	return nil
+}
+
+// knativeService composes knative service object with provided image.
+// Service parameters must be validated before the call.
+func (s *Service) knativeService(image string) *servingv1.Service {
+	traffic, _ := s.trafficTargets()
+	if s.tagsOnly() {
+		traffic = mergeTags(nil, traffic)
+	}
+	resources, _ := s.resourceRequirements()
+	scaling, _ := s.scalingAnnotations()
+
+	annotations := make(map[string]string)
+	for k, v := range s.Annotations {
+		annotations[k] = v
+	}
+	for k, v := range scaling {
+		annotations[k] = v
+	}
+
+	service := &servingv1.Service{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Service",
+			APIVersion: "serving.knative.dev/v1",
+		},
+	}
+
+	concurrency := int64(s.Concurrency)
+	configuration := servingv1.ConfigurationSpec{
+		Template: servingv1.RevisionTemplateSpec{
+			Spec: servingv1.RevisionSpec{
+				ContainerConcurrency: &concurrency,
+				PodSpec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{Image: image},
+					},
+				},
+			},
+		},
+	}
+
+	configuration.Template.ObjectMeta = metav1.ObjectMeta{
+		CreationTimestamp: metav1.Time{Time: time.Now()},
+		Annotations:       annotations,
+		Labels:            mapFromSlice(s.Labels),
+	}
+
+	configuration.Template.ObjectMeta.GenerateName = s.Name + "-"
+	configuration.Template.ObjectMeta.Namespace = s.Namespace
+	configuration.Template.Spec.PodSpec.Containers[0].Env = s.setupEnv()
+	configuration.Template.Spec.PodSpec.Containers[0].EnvFrom = s.setupEnvSecrets()
+	configuration.Template.Spec.PodSpec.Containers[0].ImagePullPolicy = corev1.PullPolicy(s.PullPolicy)
+	configuration.Template.Spec.PodSpec.Containers[0].Resources = resources
+	configuration.Template.Spec.PodSpec.ServiceAccountName = s.ServiceAccount
+
+	service.ObjectMeta = metav1.ObjectMeta{
+		Name:              s.Name,
+		Namespace:         s.Namespace,
+		Labels:            configuration.Template.ObjectMeta.Labels,
+		CreationTimestamp: metav1.Time{Time: time.Now()},
+	}
+	service.Spec = servingv1.ServiceSpec{
+		ConfigurationSpec: configuration,
+		RouteSpec: servingv1.RouteSpec{
+			Traffic: traffic,
+		},
+	}
+	return service
+}
+
 func (s *Service) setupEnv() []corev1.EnvVar {
 	var env []corev1.EnvVar
 	for k, v := range mapFromSlice(s.Env) {
 		env = append(env, corev1.EnvVar{Name: k, Value: v})
 	}
+	// keep variables order stable to avoid spurious template changes
+	sort.Slice(env, func(i, j int) bool {
+		return env[i].Name < env[j].Name
+	})
 	return env
 }
 
@@ -190,6 +301,11 @@ func (s *Service) createOrUpdate(serviceObject *servingv1.Service, clientset *cl
 				serviceObject.Annotations["serving.knative.dev/creator"] = creator
 			}
 		}
+		if s.tagsOnly() {
+			// keep the current traffic split, only tags are changed
+			tags, _ := s.trafficTargets()
+			serviceObject.Spec.Traffic = mergeTags(service.Spec.Traffic, tags)
+		}
 		serviceObject.ObjectMeta.ResourceVersion = service.GetResourceVersion()
 		return clientset.Serving.ServingV1().Services(s.Namespace).Update(serviceObject)
 	}
@@ -282,3 +398,15 @@ func (s *Service) wait(clientset *client.ConfigSet) (string, error) {
 		}
 	}
 }
+
+// dryRunOutput encodes the object that would be sent to the cluster in the requested output format
+func dryRunOutput(object interface{}) (string, error) {
+	var obj []byte
+	var err error
+	if client.Output == "yaml" {
+		obj, err = yaml.Marshal(object)
+	} else {
+		obj, err = json.MarshalIndent(object, "", " ")
+	}
+	return string(obj), err
+}
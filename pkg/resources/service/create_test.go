@@ -36,6 +36,7 @@ func TestDryRunDeployment(t *testing.T) {
 	}
 
 	client.Dry = true
+	defer func() { client.Dry = false }()
 	clientset, err := client.NewClient("../../../testfiles/cfgfile-test.json")
 	require.NoError(t, err)
 
@@ -21,5 +21,5 @@ import (
 
 // Delete removes knative service object
 func (s *Service) Delete(clientset *client.ConfigSet) error {
-	return clientset.Serving.ServingV1alpha1().Services(s.Namespace).Delete(s.Name, &metav1.DeleteOptions{})
+	return clientset.Serving.ServingV1().Services(s.Namespace).Delete(s.Name, &metav1.DeleteOptions{})
 }
@@ -0,0 +1,224 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/file"
+)
+
+// devDebounce is the delay after the last source change before the functions are redeployed
+const devDebounce = 2 * time.Second
+
+// Dev deploys yaml manifest functions, watches their local sources and redeploys
+// changed functions until interrupted. Logs of the deployed functions are streamed to the output.
+// Functions are left running on exit unless cleanup is set.
+func (s *Service) Dev(YAML string, cleanup bool, threads int, clientset *client.ConfigSet) error {
+	services, err := s.ManifestToServices(YAML)
+	if err != nil {
+		return err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	functions := make(map[string]Service)
+	roots := make(map[string][]string)
+	for _, service := range services {
+		functions[service.Name] = service
+		if !file.IsLocal(service.Source) {
+			continue
+		}
+		root := service.Source
+		if !file.IsDir(root) {
+			root = filepath.Dir(root)
+		}
+		if root, err = filepath.Abs(root); err != nil {
+			return err
+		}
+		if _, watched := roots[root]; !watched {
+			if err := watchDir(watcher, root); err != nil {
+				return err
+			}
+		}
+		roots[root] = append(roots[root], service.Name)
+	}
+	if len(roots) == 0 {
+		clientset.Log.Warnf("Manifest has no functions with local sources, changes won't be redeployed")
+	}
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(interrupt)
+
+	// deployments run in background so that interrupt is handled while functions are being deployed,
+	// deployed channel is nil when there is no deployment in progress
+	var deployed chan struct{}
+	streams := make(map[string]string)
+	deploy := func(functions []Service) {
+		deployed = make(chan struct{})
+		go func(done chan struct{}) {
+			s.devDeploy(functions, streams, threads, clientset)
+			close(done)
+		}(deployed)
+	}
+	deploy(services)
+
+	changed := make(map[string]bool)
+	// pending is set when changes settled during the running deployment
+	pending := false
+	redeploy := func() {
+		var names []string
+		for name := range changed {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		changed = make(map[string]bool)
+		var changedFunctions []Service
+		for _, name := range names {
+			changedFunctions = append(changedFunctions, functions[name])
+		}
+		clientset.Log.Infof("Sources changed, redeploying %s", strings.Join(names, ", "))
+		deploy(changedFunctions)
+	}
+	debounce := time.NewTimer(devDebounce)
+	debounce.Stop()
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			affected := affectedFunctions(roots, event.Name)
+			if len(affected) == 0 {
+				continue
+			}
+			clientset.Log.Debugf("%s: %s", event.Op, event.Name)
+			if event.Op&fsnotify.Create != 0 && file.IsDir(event.Name) {
+				if err := watchDir(watcher, event.Name); err != nil {
+					clientset.Log.Warnf("Cannot watch %q: %s", event.Name, err)
+				}
+			}
+			for _, name := range affected {
+				changed[name] = true
+			}
+			debounce.Reset(devDebounce)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			clientset.Log.Warnf("Watch error: %s", err)
+		case <-debounce.C:
+			// changes made during deployment are redeployed after it finishes
+			if deployed != nil {
+				pending = true
+				continue
+			}
+			redeploy()
+		case <-deployed:
+			deployed = nil
+			if pending {
+				pending = false
+				redeploy()
+			}
+		case <-interrupt:
+			if deployed != nil {
+				clientset.Log.Infof("Waiting for the running deployment to finish, interrupt again to exit immediately")
+				select {
+				case <-deployed:
+				case <-interrupt:
+				}
+			}
+			if !cleanup {
+				clientset.Log.Infof("Leaving functions deployed")
+				return nil
+			}
+			for _, service := range services {
+				clientset.Log.Infof("Removing function %s", service.Name)
+				if err := service.Delete(clientset); err != nil {
+					clientset.Log.Errorf("Cannot remove function %s: %s", service.Name, err)
+				}
+			}
+			return nil
+		}
+	}
+}
+
+// devDeploy deploys functions and starts streaming logs of their new revisions.
+// Deployment errors are logged without interrupting the watch.
+func (s *Service) devDeploy(functions []Service, streams map[string]string, threads int, clientset *client.ConfigSet) {
+	if err := s.DeployFunctions(functions, false, threads, clientset); err != nil {
+		clientset.Log.Errorf("%s", err)
+	}
+	for _, function := range functions {
+		service, err := function.Get(clientset)
+		if err != nil {
+			clientset.Log.Debugf("cannot get %q service: %s", function.Name, err)
+			continue
+		}
+		revision := service.Status.LatestReadyRevisionName
+		if revision == "" || streams[function.Name] == revision {
+			continue
+		}
+		streams[function.Name] = revision
+		go func(function Service, revision string) {
+			// stream ends when revision pods are terminated
+			if err := function.Logs(revision, clientset, &corev1.PodLogOptions{Follow: true}, Output); err != nil {
+				clientset.Log.Debugf("%s logs: %s", revision, err)
+			}
+		}(function, revision)
+	}
+}
+
+// watchDir adds directory and its subdirectories that are not ignored to the watcher
+func watchDir(watcher *fsnotify.Watcher, dir string) error {
+	return file.Walk(dir, func(rel string, info os.FileInfo, err error) error {
+		if !info.IsDir() {
+			return nil
+		}
+		return watcher.Add(filepath.Join(dir, rel))
+	})
+}
+
+// affectedFunctions returns names of the functions with the changed path in their sources.
+// Paths excluded by the source ignore file don't affect the function.
+func affectedFunctions(roots map[string][]string, path string) []string {
+	var names []string
+	for root, functions := range roots {
+		rel, err := filepath.Rel(root, path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if rel == "." || !file.Ignored(root, rel, file.IsDir(path)) {
+			names = append(names, functions...)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeAffectedFunctions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tm-dev")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	for _, d := range []string{"bar/lib", "bar/node_modules", "bar/.git", "baz"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, d), 0755))
+	}
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "bar", ".tmignore"), []byte("node_modules/\n*.log\n"), 0644))
+
+	roots := map[string][]string{
+		filepath.Join(dir, "bar"): {"foo-bar", "foo-bar-v2"},
+		filepath.Join(dir, "baz"): {"foo-baz"},
+	}
+	testCases := map[string][]string{
+		"bar/main.go":               {"foo-bar", "foo-bar-v2"},
+		"bar/lib":                   {"foo-bar", "foo-bar-v2"},
+		"bar/lib/util.go":           {"foo-bar", "foo-bar-v2"},
+		"bar/debug.log":             nil,
+		"bar/node_modules/index.js": nil,
+		"bar/.git/index":            nil,
+		"baz/main.go":               {"foo-baz"},
+		"barbaz/main.go":            nil,
+		"serverless.yaml":           nil,
+	}
+	for path, functions := range testCases {
+		assert.Equal(t, functions, affectedFunctions(roots, filepath.Join(dir, path)), path)
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	require.NoError(t, err)
+	defer watcher.Close()
+	require.NoError(t, watchDir(watcher, filepath.Join(dir, "bar")))
+	for _, path := range []string{"bar", "bar/lib"} {
+		assert.NoError(t, watcher.Remove(filepath.Join(dir, path)), "%s must be watched", path)
+	}
+	for _, path := range []string{"bar/node_modules", "bar/.git"} {
+		assert.Error(t, watcher.Remove(filepath.Join(dir, path)), "%s must not be watched", path)
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/resources/event"
+	"github.com/triggermesh/tm/pkg/resources/revision"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
+)
+
+// Events returns events of the service, its configuration,
+// latest created revision and revision pods
+func (s *Service) Events(service *servingv1.Service, clientset *client.ConfigSet) ([]corev1.Event, error) {
+	objects := []metav1.Object{service}
+	configuration, err := clientset.Serving.ServingV1().Configurations(s.Namespace).Get(s.Name, metav1.GetOptions{})
+	if err == nil {
+		objects = append(objects, configuration)
+	} else if !k8serrors.IsNotFound(err) {
+		return nil, err
+	}
+	if name := service.Status.LatestCreatedRevisionName; name != "" {
+		r := revision.Revision{
+			Name:      name,
+			Namespace: s.Namespace,
+		}
+		rev, err := r.Get(clientset)
+		if err == nil {
+			objects = append(objects, rev)
+		} else if !k8serrors.IsNotFound(err) {
+			return nil, err
+		}
+		pods, err := r.Pods(clientset)
+		if err != nil {
+			return nil, err
+		}
+		for i := range pods.Items {
+			objects = append(objects, &pods.Items[i])
+		}
+	}
+	e := event.Event{Namespace: s.Namespace}
+	return e.List(clientset, objects...)
+}
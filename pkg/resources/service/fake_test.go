@@ -0,0 +1,479 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/client/fake"
+	"github.com/triggermesh/tm/pkg/file"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	k8sFake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	githubSourceFake "knative.dev/eventing-contrib/github/pkg/client/clientset/versioned/fake"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
+)
+
+func readyService(name, url string) *servingv1.Service {
+	service := &servingv1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: fake.Namespace,
+		},
+	}
+	service.Status.Conditions = duckv1.Conditions{{
+		Type:   apis.ConditionReady,
+		Status: corev1.ConditionTrue,
+	}}
+	service.Status.URL = apis.HTTP(url)
+	return service
+}
+
+func TestFakeDeployGetListDelete(t *testing.T) {
+	clientset := fake.NewConfigSet()
+	s := &Service{
+		Name:            "foo",
+		Namespace:       fake.Namespace,
+		Source:          "gcr.io/google-samples/hello-app:1.0",
+		NoDigestResolve: true,
+		Env:             []string{"FOO=bar"},
+	}
+
+	_, err := s.Deploy(clientset)
+	require.NoError(t, err)
+
+	service, err := s.Get(clientset)
+	require.NoError(t, err)
+	assert.Equal(t, s.Source, service.Spec.Template.Spec.Containers[0].Image)
+
+	s.Source = "gcr.io/google-samples/hello-app:2.0"
+	_, err = s.Deploy(clientset)
+	require.NoError(t, err)
+
+	list, err := s.List(clientset)
+	require.NoError(t, err)
+	require.Len(t, list.Items, 1)
+	assert.Equal(t, s.Source, list.Items[0].Spec.Template.Spec.Containers[0].Image)
+
+	require.NoError(t, s.Delete(clientset))
+	_, err = s.Get(clientset)
+	assert.True(t, k8serrors.IsNotFound(err))
+}
+
+func TestFakeDeployWait(t *testing.T) {
+	clientset := fake.NewConfigSet()
+	client.Wait = true
+	defer func() { client.Wait = false }()
+	fake.ScriptWatch(clientset.Serving, "services",
+		watch.Event{Type: watch.Added, Object: &servingv1.Service{}},
+		watch.Event{Type: watch.Modified, Object: readyService("foo", "foo.example.com")},
+	)
+
+	s := &Service{
+		Name:            "foo",
+		Namespace:       fake.Namespace,
+		Source:          "gcr.io/google-samples/hello-app:1.0",
+		NoDigestResolve: true,
+	}
+	output, err := s.Deploy(clientset)
+	require.NoError(t, err)
+	assert.Equal(t, "Service foo URL: http://foo.example.com", output)
+}
+
+func TestFakeDeployTriggers(t *testing.T) {
+	clientset := fake.NewConfigSet()
+	s := &Service{
+		Name:            "foo",
+		Namespace:       fake.Namespace,
+		Source:          "gcr.io/google-samples/hello-app:1.0",
+		NoDigestResolve: true,
+		Triggers: []file.Event{
+			{Broker: "events", Type: "dev.knative.foo", Filter: map[string]string{"source": "bar"}},
+		},
+	}
+	_, err := s.Deploy(clientset)
+	require.NoError(t, err)
+
+	list, err := clientset.Eventing.EventingV1beta1().Triggers(fake.Namespace).List(metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, list.Items, 1)
+	trigger := list.Items[0]
+	assert.Equal(t, "events", trigger.Spec.Broker)
+	assert.Equal(t, "foo", trigger.Spec.Subscriber.Ref.Name)
+	assert.Equal(t, "foo", trigger.Labels[serviceLabelKey])
+	assert.Equal(t, map[string]string{"type": "dev.knative.foo", "source": "bar"}, map[string]string(trigger.Spec.Filter.Attributes))
+	require.Len(t, trigger.OwnerReferences, 1)
+	assert.Equal(t, "Service", trigger.OwnerReferences[0].Kind)
+}
+
+func TestFakeDeploySubscriptions(t *testing.T) {
+	clientset := fake.NewConfigSet()
+	s := &Service{
+		Name:            "foo",
+		Namespace:       fake.Namespace,
+		Source:          "gcr.io/google-samples/hello-app:1.0",
+		NoDigestResolve: true,
+		Subscriptions: []file.Subscription{
+			{Channel: "events", Reply: "results"},
+		},
+	}
+	_, err := s.Deploy(clientset)
+	require.NoError(t, err)
+
+	list, err := clientset.Eventing.MessagingV1beta1().Subscriptions(fake.Namespace).List(metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, list.Items, 1)
+	subscription := list.Items[0]
+	assert.Equal(t, "events", subscription.Spec.Channel.Name)
+	assert.Equal(t, "foo", subscription.Spec.Subscriber.Ref.Name)
+	assert.Equal(t, "results", subscription.Spec.Reply.Ref.Name)
+	assert.Equal(t, "foo", subscription.Labels[serviceLabelKey])
+	require.Len(t, subscription.OwnerReferences, 1)
+}
+
+func TestFakeDeploySources(t *testing.T) {
+	clientset := fake.NewConfigSet()
+	s := &Service{
+		Name:            "foo",
+		Namespace:       fake.Namespace,
+		Source:          "gcr.io/google-samples/hello-app:1.0",
+		NoDigestResolve: true,
+		Sources: []file.Source{
+			{APIServer: &file.APIServerSource{
+				Resources: []file.APIResource{{Kind: "Event"}},
+			}},
+			{SinkBinding: &file.SinkBindingSource{
+				Subject: file.APIResource{Kind: "Deployment", Name: "bar"},
+			}},
+			{Container: &file.ContainerSource{
+				Image:       "gcr.io/knative-releases/heartbeats",
+				Environment: map[string]string{"PERIOD": "10"},
+			}},
+			{GitHub: &file.GitHubSource{
+				Repository:  "triggermesh/tm",
+				EventTypes:  []string{"push"},
+				AccessToken: "github:access",
+				SecretToken: "github:secret",
+			}},
+		},
+	}
+	_, err := s.Deploy(clientset)
+	require.NoError(t, err)
+
+	apiServerSources, err := clientset.Eventing.SourcesV1alpha2().ApiServerSources(fake.Namespace).List(metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, apiServerSources.Items, 1)
+	apiServerSource := apiServerSources.Items[0]
+	assert.Equal(t, "foo", apiServerSource.Labels[serviceLabelKey])
+	assert.Equal(t, "foo", apiServerSource.Spec.Sink.Ref.Name)
+	assert.Equal(t, "Reference", apiServerSource.Spec.EventMode)
+	assert.Equal(t, "v1", apiServerSource.Spec.Resources[0].APIVersion)
+	require.Len(t, apiServerSource.OwnerReferences, 1)
+
+	sinkBindings, err := clientset.Eventing.SourcesV1alpha2().SinkBindings(fake.Namespace).List(metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, sinkBindings.Items, 1)
+	assert.Equal(t, "bar", sinkBindings.Items[0].Spec.Subject.Name)
+	assert.Equal(t, "apps/v1", sinkBindings.Items[0].Spec.Subject.APIVersion)
+
+	containerSources, err := clientset.Eventing.SourcesV1alpha2().ContainerSources(fake.Namespace).List(metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, containerSources.Items, 1)
+	container := containerSources.Items[0].Spec.Template.Spec.Containers[0]
+	assert.Equal(t, "gcr.io/knative-releases/heartbeats", container.Image)
+	assert.Equal(t, "PERIOD", container.Env[0].Name)
+
+	gitHubSources, err := clientset.GithubSource.SourcesV1alpha1().GitHubSources(fake.Namespace).List(metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, gitHubSources.Items, 1)
+	gitHubSource := gitHubSources.Items[0]
+	assert.Equal(t, "triggermesh/tm", gitHubSource.Spec.OwnerAndRepository)
+	assert.Equal(t, "github", gitHubSource.Spec.AccessToken.SecretKeyRef.Name)
+	assert.Equal(t, "secret", gitHubSource.Spec.SecretToken.SecretKeyRef.Key)
+	assert.Equal(t, "foo", gitHubSource.Labels[serviceLabelKey])
+}
+
+func TestFakeDeployUnchangedSources(t *testing.T) {
+	clientset := fake.NewConfigSet()
+	gitHubClient := clientset.GithubSource.(*githubSourceFake.Clientset)
+	s := &Service{
+		Name:            "foo",
+		Namespace:       fake.Namespace,
+		Source:          "gcr.io/google-samples/hello-app:1.0",
+		NoDigestResolve: true,
+		Sources: []file.Source{
+			{Container: &file.ContainerSource{
+				Image: "gcr.io/knative-releases/heartbeats",
+				Args:  []string{"--period=1"},
+			}},
+			{GitHub: &file.GitHubSource{
+				Repository:  "triggermesh/tm",
+				EventTypes:  []string{"push"},
+				AccessToken: "github:access",
+				SecretToken: "github:secret",
+			}},
+		},
+	}
+	_, err := s.Deploy(clientset)
+	require.NoError(t, err)
+	gitHubClient.ClearActions()
+
+	sources, err := s.planSources(clientset)
+	require.NoError(t, err)
+	assert.Empty(t, sources)
+
+	s.Sources[0].Container.Args = []string{"--period=5"}
+	sources, err = s.planSources(clientset)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		`- source  container "gcr.io/knative-releases/heartbeats"`,
+		`+ source container "gcr.io/knative-releases/heartbeats"`,
+		`  ~ template.spec.containers[0].args[0]: "--period=1" -> "--period=5"`,
+	}, sources)
+
+	_, err = s.Deploy(clientset)
+	require.NoError(t, err)
+	containerSources, err := clientset.Eventing.SourcesV1alpha2().ContainerSources(fake.Namespace).List(metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, containerSources.Items, 1)
+	assert.Equal(t, []string{"--period=5"}, containerSources.Items[0].Spec.Template.Spec.Containers[0].Args)
+
+	// GitHubSource is not changed and must not be recreated
+	for _, action := range gitHubClient.Actions() {
+		assert.Equal(t, "list", action.GetVerb())
+	}
+}
+
+func TestFakeExistingSourcesWithoutGitHubSource(t *testing.T) {
+	clientset := fake.NewConfigSet()
+	// clusters without eventing-contrib do not serve GitHubSource API
+	clientset.GithubSource.(*githubSourceFake.Clientset).PrependReactor("list", "githubsources", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, k8serrors.NewNotFound(schema.GroupResource{Resource: "githubsources"}, "")
+	})
+	s := &Service{
+		Name:            "foo",
+		Namespace:       fake.Namespace,
+		Source:          "gcr.io/google-samples/hello-app:1.0",
+		NoDigestResolve: true,
+		Sources: []file.Source{
+			{Container: &file.ContainerSource{Image: "gcr.io/knative-releases/heartbeats"}},
+		},
+	}
+	_, err := s.Deploy(clientset)
+	require.NoError(t, err)
+
+	sources, err := s.existingSources(clientset)
+	require.NoError(t, err)
+	assert.Len(t, sources, 1)
+}
+
+func TestFakeDeployInvalidSources(t *testing.T) {
+	testCases := map[string]file.Source{
+		"no kind": {},
+		"several kinds": {
+			Container: &file.ContainerSource{Image: "foo"},
+			GitHub:    &file.GitHubSource{Repository: "foo/bar"},
+		},
+		"apiserver without resources": {APIServer: &file.APIServerSource{}},
+		"apiserver unknown mode": {APIServer: &file.APIServerSource{
+			Resources: []file.APIResource{{Kind: "Event"}},
+			Mode:      "Full",
+		}},
+		"sinkbinding name and labels": {SinkBinding: &file.SinkBindingSource{
+			Subject: file.APIResource{Kind: "Deployment", Name: "bar", Labels: map[string]string{"app": "bar"}},
+		}},
+		"container without image": {Container: &file.ContainerSource{}},
+		"github malformed token": {GitHub: &file.GitHubSource{
+			Repository:  "triggermesh/tm",
+			AccessToken: "github",
+			SecretToken: "github:secret",
+		}},
+	}
+	for name, source := range testCases {
+		t.Run(name, func(t *testing.T) {
+			s := &Service{
+				Name:      "foo",
+				Namespace: fake.Namespace,
+				Source:    "gcr.io/google-samples/hello-app:1.0",
+				Sources:   []file.Source{source},
+			}
+			_, err := s.Deploy(fake.NewConfigSet())
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestFakeDeployPinsImage(t *testing.T) {
+	manifest := `{"schemaVersion": 2, "mediaType": "application/vnd.docker.distribution.manifest.v2+json", "layers": []}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v2/":
+			w.WriteHeader(http.StatusOK)
+		case strings.HasSuffix(r.URL.Path, "/manifests/1.0"):
+			w.Header().Set("Content-Type", "application/vnd.docker.distribution.manifest.v2+json")
+			w.Write([]byte(manifest))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	clientset := fake.NewConfigSet()
+	clientset.Registry.SkipTLS = true
+	host := strings.TrimPrefix(server.URL, "http://")
+	digest := fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(manifest)))
+
+	s := &Service{
+		Name:      "foo",
+		Namespace: fake.Namespace,
+		Source:    host + "/hello-app:1.0",
+	}
+	_, err := s.Deploy(clientset)
+	require.NoError(t, err)
+	service, err := s.Get(clientset)
+	require.NoError(t, err)
+	assert.Equal(t, host+"/hello-app@"+digest, service.Spec.Template.Spec.Containers[0].Image)
+
+	s.Source = host + "/hello-app:missing"
+	_, err = s.Deploy(clientset)
+	require.NoError(t, err)
+	service, err = s.Get(clientset)
+	require.NoError(t, err)
+	assert.Equal(t, s.Source, service.Spec.Template.Spec.Containers[0].Image, "unresolved image must be deployed by tag")
+
+	s.Source = host + "/hello-app:1.0"
+	s.NoDigestResolve = true
+	_, err = s.Deploy(clientset)
+	require.NoError(t, err)
+	service, err = s.Get(clientset)
+	require.NoError(t, err)
+	assert.Equal(t, s.Source, service.Spec.Template.Spec.Containers[0].Image)
+}
+
+func TestFakeDeployServiceAccount(t *testing.T) {
+	parent := &Service{Namespace: fake.Namespace, ServiceAccount: "runtime"}
+	service := parent.serviceObject(file.Function{Source: "gcr.io/foo/bar", ServiceAccount: "function"})
+	assert.Equal(t, "function", service.ServiceAccount)
+	assert.Equal(t, "function", service.taskRun().ServiceAccount)
+
+	parent.BuildServiceAccount = "builder"
+	service = parent.serviceObject(file.Function{Source: "gcr.io/foo/bar"})
+	assert.Equal(t, "runtime", service.ServiceAccount)
+	assert.Equal(t, "builder", service.taskRun().ServiceAccount)
+
+	clientset := fake.NewConfigSet()
+	service.Name = "foo"
+	service.NoDigestResolve = true
+	_, err := service.Deploy(clientset)
+	require.NoError(t, err)
+	ksvc, err := service.Get(clientset)
+	require.NoError(t, err)
+	assert.Equal(t, "runtime", ksvc.Spec.Template.Spec.ServiceAccountName)
+}
+
+func TestFakeLogs(t *testing.T) {
+	service := readyService("foo", "foo.example.com")
+	service.Status.LatestReadyRevisionName = "foo-00002"
+	newPod := func(name, revision string) *corev1.Pod {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: fake.Namespace,
+			Labels:    map[string]string{"serving.knative.dev/revision": revision},
+		}}
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+			Name:  "user-container",
+			State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+		}}
+		return pod
+	}
+	clientset := fake.NewConfigSet(
+		service,
+		readyService("bar", "bar.example.com"),
+		newPod("foo-00001-pod", "foo-00001"),
+		newPod("foo-00002-pod", "foo-00002"),
+	)
+	// pods watch is closed right away to stop following the logs
+	clientset.Core.(*k8sFake.Clientset).PrependWatchReactor("pods", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		w := watch.NewFake()
+		w.Stop()
+		return true, w, nil
+	})
+	fake.ScriptLogs(clientset, map[string]string{
+		"foo-00001-pod/user-container": "old\n",
+		"foo-00002-pod/user-container": "new\n",
+	})
+
+	testCases := []struct {
+		name     string
+		service  string
+		revision string
+		follow   bool
+		logs     string
+		wantErr  string
+	}{
+		{
+			name:    "latest ready revision",
+			service: "foo",
+			logs:    "[foo-00002-pod] new\n",
+		}, {
+			name:     "revision",
+			service:  "foo",
+			revision: "foo-00001",
+			logs:     "[foo-00001-pod] old\n",
+		}, {
+			name:    "follow latest ready revision",
+			service: "foo",
+			follow:  true,
+			logs:    "[foo-00002-pod] new\n",
+		}, {
+			name:    "no ready revisions",
+			service: "bar",
+			wantErr: `service "bar" has no ready revisions`,
+		}, {
+			name:    "missing service",
+			service: "baz",
+			wantErr: `services.serving.knative.dev "baz" not found`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var output bytes.Buffer
+			s := &Service{Name: tc.service, Namespace: fake.Namespace}
+			err := s.Logs(tc.revision, clientset, &corev1.PodLogOptions{Follow: tc.follow}, &output)
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.logs, output.String())
+		})
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/resources/revision"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Logs writes user container logs of the service revision pods to the output.
+// If revision name is empty, latest ready revision is used.
+func (s *Service) Logs(rev string, clientset *client.ConfigSet, options *corev1.PodLogOptions, output io.Writer) error {
+	if rev == "" {
+		service, err := s.Get(clientset)
+		if err != nil {
+			return err
+		}
+		if rev = service.Status.LatestReadyRevisionName; rev == "" {
+			return fmt.Errorf("service %q has no ready revisions", s.Name)
+		}
+	}
+	r := revision.Revision{
+		Name:      rev,
+		Namespace: s.Namespace,
+	}
+	return r.Logs(clientset, options, output)
+}
@@ -15,22 +15,22 @@
 package service
 
 import (
-	"os"
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+	eventingv1alpha2 "knative.dev/eventing/pkg/apis/sources/v1alpha2"
+	eventingFake "knative.dev/eventing/pkg/client/clientset/versioned/fake"
 
-	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/client/fake"
 	"github.com/triggermesh/tm/pkg/file"
 )
 
 func TestPingSource(t *testing.T) {
-	namespace := "test-namespace"
-	if ns, ok := os.LookupEnv("NAMESPACE"); ok {
-		namespace = ns
-	}
-
 	testCases := []struct {
 		name              string
 		service           *Service
@@ -65,17 +65,23 @@ func TestPingSource(t *testing.T) {
 		},
 	}
 
-	client.Dry = false
-	client.Wait = false
-	serviceClient, err := client.NewClient(client.ConfigPath(""))
-	assert.NoError(t, err)
-
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			tc.service.Namespace = namespace
-			_, err := tc.service.Deploy(&serviceClient)
-			assert.NoError(t, err)
-			defer tc.service.Delete(&serviceClient)
+			serviceClient := fake.NewConfigSet()
+			// PingSource validation is done by the eventing webhook in the cluster
+			serviceClient.Eventing.(*eventingFake.Clientset).PrependReactor("create", "pingsources", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				ps := action.(k8stesting.CreateAction).GetObject().(*eventingv1alpha2.PingSource)
+				if err := ps.Validate(context.Background()); err != nil {
+					return true, nil, err
+				}
+				return false, nil, nil
+			})
+
+			tc.service.Namespace = fake.Namespace
+			tc.service.NoDigestResolve = true
+			_, err := tc.service.Deploy(serviceClient)
+			require.NoError(t, err)
+			defer tc.service.Delete(serviceClient)
 
 			psList, err := serviceClient.Eventing.SourcesV1alpha2().PingSources(tc.service.Namespace).List(metav1.ListOptions{
 				LabelSelector: serviceLabelKey + "=" + tc.service.Name,
@@ -0,0 +1,461 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	eventingv1beta1 "knative.dev/eventing/pkg/apis/eventing/v1beta1"
+	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
+
+	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/file"
+	"github.com/triggermesh/tm/pkg/registry"
+)
+
+// Plan actions
+const (
+	ActionCreate    = "create"
+	ActionUpdate    = "update"
+	ActionDelete    = "delete"
+	ActionUnchanged = "unchanged"
+)
+
+// Change describes the difference between desired and existing service
+type Change struct {
+	Name    string
+	Action  string
+	Details []string
+}
+
+// fields that are fully managed by tm, keys that are missing
+// in the desired object are reported as removed
+var ownedFields = []string{
+	"metadata.labels",
+	"spec.template.metadata.annotations",
+	"spec.template.metadata.labels",
+	"spec.template.spec.containers[0].env",
+	"spec.template.spec.containers[0].envFrom",
+	"spec.traffic",
+}
+
+// PlanYAML compares functions from YAML manifest with the services deployed
+// in the cluster and prints the summary of changes that deployment would make
+func (s *Service) PlanYAML(yamlFile string, functionsToDeploy []string, clientset *client.ConfigSet) error {
+	services, err := s.ManifestToServices(yamlFile)
+	if err != nil {
+		return err
+	}
+
+	var functions []Service
+	for _, service := range services {
+		if s.inList(service.Name, functionsToDeploy) {
+			functions = append(functions, service)
+		}
+	}
+	sort.Slice(functions, func(i, j int) bool {
+		return functions[i].Name < functions[j].Name
+	})
+
+	var changes []Change
+	for _, function := range functions {
+		change, err := function.Plan(clientset)
+		if err != nil {
+			return fmt.Errorf("function %q: %s", function.Name, err)
+		}
+		changes = append(changes, change)
+	}
+
+	if len(functionsToDeploy) == 0 {
+		orphans, err := s.orphans(functions, clientset)
+		if err != nil {
+			return err
+		}
+		for _, orphan := range orphans {
+			changes = append(changes, Change{
+				Name:    orphan,
+				Action:  ActionDelete,
+				Details: []string{"orphaned function is not defined in manifest"},
+			})
+		}
+	}
+
+	printChanges(changes)
+	return nil
+}
+
+// Plan compares Service with existing knative service and returns the list of differences
+func (s *Service) Plan(clientset *client.ConfigSet) (Change, error) {
+	change := Change{
+		Name:   s.Name,
+		Action: ActionUnchanged,
+	}
+
+	if err := s.validate(); err != nil {
+		return change, err
+	}
+
+	existing, err := s.Get(clientset)
+	if k8serrors.IsNotFound(err) {
+		change.Action = ActionCreate
+		for _, sched := range s.Schedule {
+			change.Details = append(change.Details, fmt.Sprintf("+ pingsource %q", sched.Cron))
+		}
+		for _, event := range s.Triggers {
+			t, err := s.eventTrigger(event)
+			if err != nil {
+				return change, err
+			}
+			change.Details = append(change.Details, "+ trigger "+triggerSummary(t.Spec))
+		}
+		for _, sub := range s.Subscriptions {
+			change.Details = append(change.Details, "+ subscription "+subscriptionSummary(sub.Channel, sub.Reply))
+		}
+		sources, err := s.desiredSources()
+		if err != nil {
+			return change, err
+		}
+		for _, source := range sources {
+			change.Details = append(change.Details, "+ source "+source.summary)
+		}
+		return change, nil
+	} else if err != nil {
+		return change, err
+	}
+
+	image := s.Source
+	if file.IsLocal(s.Source) || file.IsGit(s.Source) {
+		// image built from the same sources is reused by deployment
+		builder := s.taskRun()
+		if cached, err := builder.CachedImage(clientset); err == nil {
+			image = cached
+			if !s.NoDigestResolve {
+				image = s.pinImage(image, builder, clientset)
+			}
+		} else {
+			if err != registry.ErrNotFound {
+				clientset.Log.Debugf("can't check cached image: %s", err)
+			}
+			change.Details = append(change.Details, fmt.Sprintf("~ image will be rebuilt from %q", s.Source))
+			if len(existing.Spec.Template.Spec.Containers) != 0 {
+				image = existing.Spec.Template.Spec.Containers[0].Image
+			}
+		}
+	} else if !s.NoDigestResolve {
+		image = s.pinImage(image, nil, clientset)
+	}
+	desired := s.knativeService(image)
+	if len(desired.Spec.Traffic) == 0 {
+		desired.Spec.Traffic = []servingv1.TrafficTarget{trafficTarget(latestRevision, "", 100)}
+	}
+
+	diff, err := diffObjects(existing, desired)
+	if err != nil {
+		return change, err
+	}
+	change.Details = append(change.Details, diff...)
+
+	schedule, err := s.planPingSources(clientset)
+	if err != nil {
+		return change, err
+	}
+	change.Details = append(change.Details, schedule...)
+
+	triggers, err := s.planTriggers(clientset)
+	if err != nil {
+		return change, err
+	}
+	change.Details = append(change.Details, triggers...)
+
+	subscriptions, err := s.planSubscriptions(clientset)
+	if err != nil {
+		return change, err
+	}
+	change.Details = append(change.Details, subscriptions...)
+
+	sources, err := s.planSources(clientset)
+	if err != nil {
+		return change, err
+	}
+	change.Details = append(change.Details, sources...)
+
+	if len(change.Details) != 0 {
+		change.Action = ActionUpdate
+	}
+	return change, nil
+}
+
+// planPingSources reports existing PingSources that would be replaced by the new schedule
+func (s *Service) planPingSources(clientset *client.ConfigSet) ([]string, error) {
+	list, err := clientset.Eventing.SourcesV1alpha2().PingSources(s.Namespace).List(metav1.ListOptions{
+		LabelSelector: serviceLabelKey + "=" + s.Name,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var existing, desired []string
+	for _, ps := range list.Items {
+		existing = append(existing, ps.Spec.Schedule+" "+ps.Spec.JsonData)
+	}
+	for _, sched := range s.Schedule {
+		desired = append(desired, sched.Cron+" "+sched.JSONData)
+	}
+	sort.Strings(existing)
+	sort.Strings(desired)
+	if strings.Join(existing, "\n") == strings.Join(desired, "\n") {
+		return nil, nil
+	}
+
+	var details []string
+	for _, ps := range list.Items {
+		details = append(details, fmt.Sprintf("- pingsource %s %q", ps.Name, ps.Spec.Schedule))
+	}
+	for _, sched := range s.Schedule {
+		details = append(details, fmt.Sprintf("+ pingsource %q", sched.Cron))
+	}
+	return details, nil
+}
+
+// planTriggers reports existing Triggers that would be replaced by the new events list
+func (s *Service) planTriggers(clientset *client.ConfigSet) ([]string, error) {
+	list, err := clientset.Eventing.EventingV1beta1().Triggers(s.Namespace).List(metav1.ListOptions{
+		LabelSelector: serviceLabelKey + "=" + s.Name,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var existing, desired []string
+	for _, t := range list.Items {
+		existing = append(existing, triggerSummary(t.Spec))
+	}
+	for _, event := range s.Triggers {
+		t, err := s.eventTrigger(event)
+		if err != nil {
+			return nil, err
+		}
+		desired = append(desired, triggerSummary(t.Spec))
+	}
+	sort.Strings(existing)
+	sort.Strings(desired)
+	if strings.Join(existing, "\n") == strings.Join(desired, "\n") {
+		return nil, nil
+	}
+
+	var details []string
+	for _, t := range list.Items {
+		details = append(details, fmt.Sprintf("- trigger %s %s", t.Name, triggerSummary(t.Spec)))
+	}
+	for _, d := range desired {
+		details = append(details, "+ trigger "+d)
+	}
+	return details, nil
+}
+
+// triggerSummary returns trigger broker and sorted filter attributes as a string
+func triggerSummary(spec eventingv1beta1.TriggerSpec) string {
+	var filter []string
+	if spec.Filter != nil {
+		for k, v := range spec.Filter.Attributes {
+			filter = append(filter, k+"="+v)
+		}
+	}
+	sort.Strings(filter)
+	return fmt.Sprintf("%s [%s]", spec.Broker, strings.Join(filter, ","))
+}
+
+// planSubscriptions reports existing Subscriptions that would be replaced by the new subscriptions list
+func (s *Service) planSubscriptions(clientset *client.ConfigSet) ([]string, error) {
+	list, err := clientset.Eventing.MessagingV1beta1().Subscriptions(s.Namespace).List(metav1.ListOptions{
+		LabelSelector: serviceLabelKey + "=" + s.Name,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var details, existing, desired []string
+	for _, sub := range list.Items {
+		reply := ""
+		if sub.Spec.Reply != nil && sub.Spec.Reply.Ref != nil {
+			reply = sub.Spec.Reply.Ref.Name
+		}
+		summary := subscriptionSummary(sub.Spec.Channel.Name, reply)
+		existing = append(existing, summary)
+		details = append(details, fmt.Sprintf("- subscription %s %s", sub.Name, summary))
+	}
+	for _, sub := range s.Subscriptions {
+		desired = append(desired, subscriptionSummary(sub.Channel, sub.Reply))
+	}
+	sort.Strings(existing)
+	sort.Strings(desired)
+	if strings.Join(existing, "\n") == strings.Join(desired, "\n") {
+		return nil, nil
+	}
+
+	for _, d := range desired {
+		details = append(details, "+ subscription "+d)
+	}
+	return details, nil
+}
+
+// planSources reports existing event sources that would be replaced by the new sources list
+func (s *Service) planSources(clientset *client.ConfigSet) ([]string, error) {
+	existing, err := s.existingSources(clientset)
+	if err != nil {
+		return nil, err
+	}
+	desired, err := s.desiredSources()
+	if err != nil {
+		return nil, err
+	}
+	stale, missing := matchSources(existing, desired)
+
+	var details []string
+	for _, e := range stale {
+		details = append(details, fmt.Sprintf("- source %s %s", e.name, e.summary))
+	}
+	for _, d := range missing {
+		details = append(details, "+ source "+d.summary)
+		// show what differs from the replaced source of the same kind and target
+		for _, e := range stale {
+			if e.kind == d.kind && e.summary == d.summary {
+				for _, field := range diffFields(e.spec, d.spec, sourceTracked, sourceOwned) {
+					details = append(details, "  "+field)
+				}
+				break
+			}
+		}
+	}
+	return details, nil
+}
+
+func subscriptionSummary(channel, reply string) string {
+	if reply == "" {
+		return channel
+	}
+	return channel + " -> " + reply
+}
+
+// diffObjects compares object fields that are set by tm and returns
+// the list of human-readable differences
+func diffObjects(existing, desired *servingv1.Service) ([]string, error) {
+	have, err := flatten(existing)
+	if err != nil {
+		return nil, err
+	}
+	want, err := flatten(desired)
+	if err != nil {
+		return nil, err
+	}
+	return diffFields(have, want, tracked, owned), nil
+}
+
+// diffFields compares flattened objects. Paths that are not tracked are ignored,
+// owned paths that are missing in the desired object are reported as removed.
+func diffFields(have, want map[string]string, tracked, owned func(string) bool) []string {
+	var diff []string
+	for path, value := range want {
+		if !tracked(path) {
+			continue
+		}
+		old, exists := have[path]
+		switch {
+		case !exists:
+			diff = append(diff, fmt.Sprintf("+ %s: %s", path, value))
+		case old != value:
+			diff = append(diff, fmt.Sprintf("~ %s: %s -> %s", path, old, value))
+		}
+	}
+	for path, value := range have {
+		if _, exists := want[path]; exists || !tracked(path) || !owned(path) {
+			continue
+		}
+		diff = append(diff, fmt.Sprintf("- %s: %s", path, value))
+	}
+	sort.Slice(diff, func(i, j int) bool {
+		return diff[i][2:] < diff[j][2:]
+	})
+	return diff
+}
+
+func tracked(path string) bool {
+	if !strings.HasPrefix(path, "spec.") && !strings.HasPrefix(path, "metadata.labels") {
+		return false
+	}
+	return !strings.HasSuffix(path, "creationTimestamp")
+}
+
+func owned(path string) bool {
+	for _, field := range ownedFields {
+		if strings.HasPrefix(path, field) {
+			return true
+		}
+	}
+	return false
+}
+
+// flatten converts object into the map of JSON paths and encoded leaf values
+func flatten(object interface{}) (map[string]string, error) {
+	data, err := json.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
+	var tree interface{}
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return nil, err
+	}
+	result := make(map[string]string)
+	flattenValue("", tree, result)
+	return result, nil
+}
+
+func flattenValue(prefix string, value interface{}, result map[string]string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			path := key
+			if prefix != "" {
+				path = prefix + "." + key
+			}
+			flattenValue(path, field, result)
+		}
+	case []interface{}:
+		for i, item := range v {
+			flattenValue(fmt.Sprintf("%s[%d]", prefix, i), item, result)
+		}
+	default:
+		data, _ := json.Marshal(v)
+		result[prefix] = string(data)
+	}
+}
+
+func printChanges(changes []Change) {
+	summary := make(map[string]int)
+	for _, change := range changes {
+		summary[change.Action]++
+		fmt.Fprintf(Output, "%s %s\n", change.Name, change.Action)
+		for _, detail := range change.Details {
+			fmt.Fprintf(Output, "    %s\n", detail)
+		}
+	}
+	fmt.Fprintf(Output, "\n%d to create, %d to update, %d to delete, %d unchanged\n",
+		summary[ActionCreate], summary[ActionUpdate], summary[ActionDelete], summary[ActionUnchanged])
+}
@@ -0,0 +1,119 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/triggermesh/tm/pkg/client/fake"
+	"github.com/triggermesh/tm/pkg/registry"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
+)
+
+func TestDiffObjects(t *testing.T) {
+	s := &Service{
+		Name:      "foo",
+		Namespace: "test-namespace",
+		Env:       []string{"FOO=bar", "BAZ=qux"},
+		Labels:    []string{"service:foo"},
+	}
+	existing := s.knativeService("gcr.io/foo:v1")
+
+	diff, err := diffObjects(existing, s.knativeService("gcr.io/foo:v1"))
+	require.NoError(t, err)
+	assert.Empty(t, diff)
+
+	s.Env = []string{"FOO=bar"}
+	s.Annotations = map[string]string{"Description": "foo function"}
+	diff, err = diffObjects(existing, s.knativeService("gcr.io/foo:v2"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		`+ spec.template.metadata.annotations.Description: "foo function"`,
+		`~ spec.template.spec.containers[0].env[0].name: "BAZ" -> "FOO"`,
+		`~ spec.template.spec.containers[0].env[0].value: "qux" -> "bar"`,
+		`- spec.template.spec.containers[0].env[1].name: "FOO"`,
+		`- spec.template.spec.containers[0].env[1].value: "bar"`,
+		`~ spec.template.spec.containers[0].image: "gcr.io/foo:v1" -> "gcr.io/foo:v2"`,
+	}, diff)
+}
+
+func TestFakePlanCachedImage(t *testing.T) {
+	imageExists := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v2/":
+			w.WriteHeader(http.StatusOK)
+		case strings.Contains(r.URL.Path, "/manifests/") && imageExists:
+			w.Header().Set("Content-Type", "application/vnd.docker.distribution.manifest.v2+json")
+			w.Write([]byte(`{"schemaVersion": 2, "mediaType": "application/vnd.docker.distribution.manifest.v2+json", "layers": []}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "tm-plan")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "main.go"), []byte("package main"), 0644))
+
+	s := &Service{
+		Name:      "foo",
+		Namespace: fake.Namespace,
+		Source:    dir,
+		Runtime:   "kaniko",
+	}
+	task := &v1beta1.Task{
+		ObjectMeta: metav1.ObjectMeta{Name: "kaniko", Namespace: fake.Namespace},
+		Spec: v1beta1.TaskSpec{
+			Steps: []v1beta1.Step{{Container: corev1.Container{Image: "kaniko:v1"}}},
+		},
+	}
+	clientset := fake.NewConfigSet(task)
+	clientset.Registry.Host = strings.TrimPrefix(server.URL, "http://")
+	clientset.Registry.SkipTLS = true
+
+	builder := s.taskRun()
+	image, err := builder.CachedImage(clientset)
+	require.NoError(t, err)
+	image, err = registry.Pin(image, builder.ImageDigest())
+	require.NoError(t, err)
+	existing := s.knativeService(image)
+	existing.Spec.Traffic = []servingv1.TrafficTarget{trafficTarget(latestRevision, "", 100)}
+	_, err = clientset.Serving.ServingV1().Services(fake.Namespace).Create(existing)
+	require.NoError(t, err)
+
+	change, err := s.Plan(clientset)
+	require.NoError(t, err)
+	assert.Equal(t, ActionUnchanged, change.Action)
+	assert.Empty(t, change.Details)
+
+	imageExists = false
+	change, err = s.Plan(clientset)
+	require.NoError(t, err)
+	assert.Equal(t, ActionUpdate, change.Action)
+	assert.Equal(t, []string{`~ image will be rebuilt from "` + dir + `"`}, change.Details)
+}
@@ -0,0 +1,97 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"fmt"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// knative autoscaler revision annotations
+const (
+	minScaleAnnotation          = "autoscaling.knative.dev/minScale"
+	maxScaleAnnotation          = "autoscaling.knative.dev/maxScale"
+	targetAnnotation            = "autoscaling.knative.dev/target"
+	targetUtilizationAnnotation = "autoscaling.knative.dev/targetUtilizationPercentage"
+)
+
+// resourceRequirements validates requested compute resources
+// and converts them into container resource requirements
+func (s *Service) resourceRequirements() (corev1.ResourceRequirements, error) {
+	var res corev1.ResourceRequirements
+	var err error
+	if res.Requests, err = resourceList(s.Requests); err != nil {
+		return res, fmt.Errorf("requests: %s", err)
+	}
+	if res.Limits, err = resourceList(s.Limits); err != nil {
+		return res, fmt.Errorf("limits: %s", err)
+	}
+	for name, request := range res.Requests {
+		if limit, ok := res.Limits[name]; ok && request.Cmp(limit) > 0 {
+			return res, fmt.Errorf("%s request %s exceeds limit %s", name, request.String(), limit.String())
+		}
+	}
+	return res, nil
+}
+
+func resourceList(resources map[string]string) (corev1.ResourceList, error) {
+	if len(resources) == 0 {
+		return nil, nil
+	}
+	list := corev1.ResourceList{}
+	for k, v := range resources {
+		name := corev1.ResourceName(k)
+		if name != corev1.ResourceCPU && name != corev1.ResourceMemory {
+			return nil, fmt.Errorf("unsupported resource %q, only %q and %q are allowed", k, corev1.ResourceCPU, corev1.ResourceMemory)
+		}
+		quantity, err := resource.ParseQuantity(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s quantity %q: %s", k, v, err)
+		}
+		list[name] = quantity
+	}
+	return list, nil
+}
+
+// scalingAnnotations validates autoscaling parameters
+// and returns corresponding revision annotations
+func (s *Service) scalingAnnotations() (map[string]string, error) {
+	annotations := make(map[string]string)
+	if s.MinScale < 0 || s.MaxScale < 0 || s.Target < 0 {
+		return nil, fmt.Errorf("scaling parameters cannot be negative")
+	}
+	if s.MaxScale != 0 && s.MinScale > s.MaxScale {
+		return nil, fmt.Errorf("min scale %d is greater than max scale %d", s.MinScale, s.MaxScale)
+	}
+	if s.TargetUtilization < 0 || s.TargetUtilization > 100 {
+		return nil, fmt.Errorf("target utilization must be in 1-100 range")
+	}
+	if s.MinScale != 0 {
+		annotations[minScaleAnnotation] = strconv.Itoa(s.MinScale)
+	}
+	if s.MaxScale != 0 {
+		annotations[maxScaleAnnotation] = strconv.Itoa(s.MaxScale)
+	}
+	if s.Target != 0 {
+		annotations[targetAnnotation] = strconv.Itoa(s.Target)
+	}
+	if s.TargetUtilization != 0 {
+		annotations[targetUtilizationAnnotation] = strconv.Itoa(s.TargetUtilization)
+	}
+	return annotations, nil
+}
@@ -0,0 +1,91 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestResourceRequirements(t *testing.T) {
+	testCases := []struct {
+		name     string
+		requests map[string]string
+		limits   map[string]string
+		wantErr  bool
+	}{
+		{
+			name:     "valid resources",
+			requests: map[string]string{"cpu": "100m", "memory": "128Mi"},
+			limits:   map[string]string{"cpu": "1", "memory": "512Mi"},
+		}, {
+			name:     "unsupported resource",
+			requests: map[string]string{"gpu": "1"},
+			wantErr:  true,
+		}, {
+			name:     "malformed quantity",
+			requests: map[string]string{"memory": "lots"},
+			wantErr:  true,
+		}, {
+			name:     "request exceeds limit",
+			requests: map[string]string{"cpu": "2"},
+			limits:   map[string]string{"cpu": "500m"},
+			wantErr:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &Service{Requests: tc.requests, Limits: tc.limits}
+			res, err := s.resourceRequirements()
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.requests["cpu"], res.Requests.Cpu().String())
+			assert.Equal(t, tc.limits["memory"], res.Limits.Memory().String())
+		})
+	}
+}
+
+func TestScalingAnnotations(t *testing.T) {
+	s := &Service{
+		Name:              "foo",
+		Annotations:       map[string]string{"Description": "foo"},
+		MinScale:          1,
+		MaxScale:          5,
+		TargetUtilization: 70,
+		Requests:          map[string]string{"memory": "64Mi"},
+	}
+	require.NoError(t, s.validate())
+
+	service := s.knativeService("gcr.io/foo")
+	annotations := service.Spec.Template.Annotations
+	assert.Equal(t, "1", annotations[minScaleAnnotation])
+	assert.Equal(t, "5", annotations[maxScaleAnnotation])
+	assert.Equal(t, "70", annotations[targetUtilizationAnnotation])
+	assert.NotContains(t, annotations, targetAnnotation)
+	assert.NotContains(t, s.Annotations, minScaleAnnotation)
+
+	memory := service.Spec.Template.Spec.Containers[0].Resources.Requests[corev1.ResourceMemory]
+	assert.Equal(t, "64Mi", memory.String())
+
+	s.MinScale = 10
+	assert.Error(t, s.validate())
+}
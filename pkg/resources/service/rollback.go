@@ -0,0 +1,115 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"fmt"
+	"sort"
+
+	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
+
+	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/resources/revision"
+)
+
+// Rollback routes all service traffic to the specified revision.
+// If revision name is empty, previous ready revision is used.
+func (s *Service) Rollback(target string, clientset *client.ConfigSet) (string, error) {
+	service, err := s.Get(clientset)
+	if err != nil {
+		return "", err
+	}
+	r := revision.Revision{
+		Namespace: s.Namespace,
+		Service:   s.Name,
+	}
+	list, err := r.List(clientset)
+	if err != nil {
+		return "", fmt.Errorf("listing revisions: %s", err)
+	}
+	if target == "" {
+		if target, err = previousRevision(service, list.Items); err != nil {
+			return "", err
+		}
+	} else if err := readyRevision(target, list.Items); err != nil {
+		return "", fmt.Errorf("service %q: %s", s.Name, err)
+	}
+
+	traffic := []servingv1.TrafficTarget{trafficTarget(target, "", 100)}
+	if client.Dry {
+		service.Spec.Traffic = traffic
+		return dryRunOutput(service)
+	}
+
+	clientset.Log.Infof("Routing %q traffic to revision %q", s.Name, target)
+	if err := s.updateTraffic(traffic, clientset); err != nil {
+		return "", fmt.Errorf("updating traffic: %s", err)
+	}
+
+	if !client.Wait {
+		return fmt.Sprintf("Service %s traffic is routed to %s", s.Name, target), nil
+	}
+
+	clientset.Log.Infof("Waiting for service %q ready state", s.Name)
+	domain, err := s.wait(clientset)
+	return fmt.Sprintf("Service %s URL: %s", s.Name, domain), err
+}
+
+// currentRevision returns the name of the revision that receives
+// the largest share of service traffic
+func currentRevision(service *servingv1.Service) string {
+	current := service.Status.LatestReadyRevisionName
+	var percent int64
+	for _, t := range service.Status.Traffic {
+		if t.Percent != nil && *t.Percent > percent {
+			current = t.RevisionName
+			percent = *t.Percent
+		}
+	}
+	return current
+}
+
+// previousRevision returns the newest ready revision created before the current one
+func previousRevision(service *servingv1.Service, revisions []servingv1.Revision) (string, error) {
+	current := currentRevision(service)
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[j].CreationTimestamp.Before(&revisions[i].CreationTimestamp)
+	})
+	seen := false
+	for _, r := range revisions {
+		if r.Name == current {
+			seen = true
+			continue
+		}
+		if seen && r.IsReady() {
+			return r.Name, nil
+		}
+	}
+	return "", fmt.Errorf("service %q has no ready revisions older than %q", service.Name, current)
+}
+
+// readyRevision returns error if revision does not exist or is not ready to receive traffic
+func readyRevision(name string, revisions []servingv1.Revision) error {
+	for _, r := range revisions {
+		if r.Name != name {
+			continue
+		}
+		if !r.IsReady() {
+			return fmt.Errorf("revision %q is not ready", name)
+		}
+		return nil
+	}
+	return fmt.Errorf("revision %q not found", name)
+}
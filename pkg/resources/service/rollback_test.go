@@ -0,0 +1,103 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/client/fake"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"knative.dev/pkg/apis"
+	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
+)
+
+func testRevision(name string, age time.Duration, ready bool) servingv1.Revision {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	r := servingv1.Revision{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-age)),
+		},
+	}
+	r.Status.SetConditions(apis.Conditions{{
+		Type:   servingv1.RevisionConditionReady,
+		Status: status,
+	}})
+	return r
+}
+
+func TestPreviousRevision(t *testing.T) {
+	revisions := []servingv1.Revision{
+		testRevision("foo-00001", 3*time.Hour, true),
+		testRevision("foo-00003", time.Hour, true),
+		testRevision("foo-00002", 2*time.Hour, false),
+	}
+
+	service := &servingv1.Service{}
+	service.Status.LatestReadyRevisionName = "foo-00003"
+	previous, err := previousRevision(service, revisions)
+	assert.NoError(t, err)
+	assert.Equal(t, "foo-00001", previous)
+
+	service.Status.LatestReadyRevisionName = "foo-00001"
+	_, err = previousRevision(service, revisions)
+	assert.Error(t, err)
+}
+
+func TestFakeRollback(t *testing.T) {
+	revisions := []servingv1.Revision{
+		testRevision("foo-00001", 2*time.Hour, true),
+		testRevision("foo-00002", time.Hour, false),
+	}
+	objects := []runtime.Object{readyService("foo", "foo.example.com")}
+	for i := range revisions {
+		revisions[i].Namespace = fake.Namespace
+		revisions[i].Labels = map[string]string{"serving.knative.dev/service": "foo"}
+		objects = append(objects, &revisions[i])
+	}
+	clientset := fake.NewConfigSet(objects...)
+	s := &Service{Name: "foo", Namespace: fake.Namespace}
+
+	_, err := s.Rollback("foo-00002", clientset)
+	assert.EqualError(t, err, `service "foo": revision "foo-00002" is not ready`)
+	_, err = s.Rollback("foo-00003", clientset)
+	assert.EqualError(t, err, `service "foo": revision "foo-00003" not found`)
+
+	// dry run prints the patched service without updating it
+	client.Dry = true
+	output, err := s.Rollback("foo-00001", clientset)
+	client.Dry = false
+	require.NoError(t, err)
+	assert.Contains(t, output, `"revisionName": "foo-00001"`)
+	service, err := s.Get(clientset)
+	require.NoError(t, err)
+	assert.Empty(t, service.Spec.Traffic)
+
+	_, err = s.Rollback("foo-00001", clientset)
+	require.NoError(t, err)
+	service, err = s.Get(clientset)
+	require.NoError(t, err)
+	require.Len(t, service.Spec.Traffic, 1)
+	assert.Equal(t, "foo-00001", service.Spec.Traffic[0].RevisionName)
+}
@@ -0,0 +1,208 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/file"
+)
+
+// containerPort is the port knative passes to the service container in PORT variable
+const containerPort = 8080
+
+// LocalRun contains parameters of the function started on the local machine
+type LocalRun struct {
+	Function    string
+	EnvFile     string
+	Port        int
+	Engine      string
+	NoContainer bool
+}
+
+// execCommand creates the local process command, replaced in tests
+var execCommand = exec.Command
+
+// RunLocal finds the function in yaml manifest and starts it on the local machine
+// with the same environment it would have in the cluster. Function image is started
+// with the container engine, Go functions can also be started from the sources.
+func (s *Service) RunLocal(YAML string, options LocalRun, clientset *client.ConfigSet) error {
+	services, err := s.ManifestToServices(YAML)
+	if err != nil {
+		return err
+	}
+	var function *Service
+	for i, service := range services {
+		if service.Name == options.Function || service.Name == fmt.Sprintf("%s-%s", s.Name, options.Function) {
+			function = &services[i]
+			break
+		}
+	}
+	if function == nil {
+		return fmt.Errorf("function %q not found in %q", options.Function, YAML)
+	}
+
+	port := containerPort
+	if options.NoContainer {
+		port = options.Port
+	}
+	env, err := function.localEnv(options.EnvFile, port, clientset)
+	if err != nil {
+		return err
+	}
+
+	var cmd *exec.Cmd
+	if options.NoContainer {
+		if cmd, err = function.sourceCommand(env); err != nil {
+			return err
+		}
+	} else {
+		image, err := function.localImage(clientset)
+		if err != nil {
+			return err
+		}
+		cmd = execCommand(options.Engine, containerArgs(function.Name, image, options.Port, env)...)
+	}
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	clientset.Log.Infof("Starting %q on http://localhost:%d", function.Name, options.Port)
+	clientset.Log.Debugf("running %q", strings.Join(cmd.Args, " "))
+	return cmd.Run()
+}
+
+// localEnv returns function environment variables in NAME=VALUE format.
+// Values of env-secrets are read from the local env file or, if the file doesn't exist, from the cluster secrets.
+// As in the cluster, manifest variables take precedence over the secrets.
+func (s *Service) localEnv(envFile string, port int, clientset *client.ConfigSet) ([]string, error) {
+	vars, err := readEnvFile(envFile)
+	switch {
+	case err == nil:
+		clientset.Log.Debugf("secret values are read from %q", envFile)
+	case !os.IsNotExist(err):
+		return nil, fmt.Errorf("reading env file: %s", err)
+	case len(s.EnvSecrets) != 0 && clientset.Core == nil:
+		clientset.Log.Warnf("Env file %q not found and there is no cluster connection, secrets %v are not set", envFile, s.EnvSecrets)
+	default:
+		for _, name := range s.EnvSecrets {
+			secret, err := clientset.Core.CoreV1().Secrets(s.Namespace).Get(name, metav1.GetOptions{})
+			if err != nil {
+				clientset.Log.Warnf("Secret %q values are not set: %s", name, err)
+				continue
+			}
+			for k, v := range secret.Data {
+				vars[k] = string(v)
+			}
+		}
+	}
+	for _, v := range s.setupEnv() {
+		vars[v.Name] = v.Value
+	}
+	vars["PORT"] = strconv.Itoa(port)
+
+	var env []string
+	for k, v := range vars {
+		env = append(env, k+"="+v)
+	}
+	sort.Strings(env)
+	return env, nil
+}
+
+// localImage returns the function image. Image of the function built from the sources
+// is taken from the service deployed in the cluster.
+func (s *Service) localImage(clientset *client.ConfigSet) (string, error) {
+	if !file.IsLocal(s.Source) && !file.IsGit(s.Source) {
+		return s.Source, nil
+	}
+	if clientset.Serving == nil {
+		return "", fmt.Errorf("function %q is built from the sources, cluster connection is required to get its image", s.Name)
+	}
+	service, err := s.Get(clientset)
+	if err != nil {
+		return "", fmt.Errorf("function %q image is not built, deploy the function or use --no-container flag: %s", s.Name, err)
+	}
+	if len(service.Spec.Template.Spec.Containers) == 0 {
+		return "", fmt.Errorf("service %q has no containers", s.Name)
+	}
+	return service.Spec.Template.Spec.Containers[0].Image, nil
+}
+
+// sourceCommand returns the command to run Go function from the local sources
+func (s *Service) sourceCommand(env []string) (*exec.Cmd, error) {
+	if !file.IsLocal(s.Source) {
+		return nil, fmt.Errorf("function %q source is not local, cannot run it without container", s.Name)
+	}
+	dir := s.Source
+	if !file.IsDir(dir) {
+		dir = path.Dir(dir)
+	}
+	if sources, _ := filepath.Glob(filepath.Join(dir, "*.go")); len(sources) == 0 {
+		return nil, fmt.Errorf("function %q is not written in Go, only Go functions can run without container", s.Name)
+	}
+	cmd := execCommand("go", "run", ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+	return cmd, nil
+}
+
+// containerArgs returns container engine arguments to run the image
+func containerArgs(name, image string, port int, env []string) []string {
+	args := []string{"run", "--rm", "--name", name, "-p", fmt.Sprintf("%d:%d", port, containerPort)}
+	for _, v := range env {
+		args = append(args, "-e", v)
+	}
+	return append(args, image)
+}
+
+// readEnvFile parses file with NAME=VALUE lines
+func readEnvFile(path string) (map[string]string, error) {
+	vars := make(map[string]string)
+	f, err := os.Open(path)
+	if err != nil {
+		return vars, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		kv := strings.SplitN(strings.TrimPrefix(text, "export "), "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			return nil, fmt.Errorf("%s:%d: expected NAME=VALUE", path, line)
+		}
+		value := strings.TrimSpace(kv[1])
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			value = unquoted
+		} else if len(value) > 1 && value[0] == '\'' && value[len(value)-1] == '\'' {
+			value = value[1 : len(value)-1]
+		}
+		vars[strings.TrimSpace(kv[0])] = value
+	}
+	return vars, scanner.Err()
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/triggermesh/tm/pkg/client/fake"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const runManifest = `service: foo
+provider:
+  name: triggermesh
+  environment:
+    FOO: bar
+  env-secrets:
+    - creds
+functions:
+  image:
+    source: gcr.io/google-samples/hello-app:1.0
+    environment:
+      FUNCTION: image
+  local:
+    source: local
+    environment:
+      FUNCTION: local
+`
+
+func TestFakeRunLocal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tm-run")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	manifest := filepath.Join(dir, "serverless.yaml")
+	require.NoError(t, ioutil.WriteFile(manifest, []byte(runManifest), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "local"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "local", "main.go"), []byte("package main"), 0644))
+	envFile := filepath.Join(dir, ".env")
+	require.NoError(t, ioutil.WriteFile(envFile, []byte("# secrets\nTOKEN=\"secret value\"\nexport FOO=overridden\n"), 0644))
+
+	var commands []*exec.Cmd
+	execCommand = func(name string, args ...string) *exec.Cmd {
+		cmd := exec.Command("true")
+		cmd.Args = append([]string{name}, args...)
+		commands = append(commands, cmd)
+		return cmd
+	}
+	defer func() { execCommand = exec.Command }()
+
+	clientset := fake.NewConfigSet(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "creds", Namespace: fake.Namespace},
+		Data:       map[string][]byte{"TOKEN": []byte("cluster value")},
+	})
+	s := &Service{Namespace: fake.Namespace}
+
+	err = s.RunLocal(manifest, LocalRun{Function: "image", EnvFile: envFile, Port: 9090, Engine: "podman"}, clientset)
+	require.NoError(t, err)
+	require.Len(t, commands, 1)
+	assert.Equal(t, []string{"podman", "run", "--rm", "--name", "foo-image", "-p", "9090:8080",
+		"-e", "FOO=bar", "-e", "FUNCTION=image", "-e", "PORT=8080", "-e", "TOKEN=secret value",
+		"gcr.io/google-samples/hello-app:1.0"}, commands[0].Args)
+
+	s = &Service{Namespace: fake.Namespace}
+	err = s.RunLocal(manifest, LocalRun{Function: "foo-local", EnvFile: filepath.Join(dir, "missing.env"), Port: 9090, NoContainer: true}, clientset)
+	require.NoError(t, err)
+	require.Len(t, commands, 2)
+	assert.Equal(t, []string{"go", "run", "."}, commands[1].Args)
+	assert.Equal(t, filepath.Join(dir, "local"), commands[1].Dir)
+	assert.Subset(t, commands[1].Env, []string{"FOO=bar", "FUNCTION=local", "PORT=9090", "TOKEN=cluster value"})
+
+	s = &Service{Namespace: fake.Namespace}
+	err = s.RunLocal(manifest, LocalRun{Function: "local", EnvFile: envFile, Port: 9090}, clientset)
+	assert.Error(t, err, "function built from the sources must be deployed before running in container")
+
+	s = &Service{Namespace: fake.Namespace}
+	err = s.RunLocal(manifest, LocalRun{Function: "image", EnvFile: envFile, NoContainer: true}, clientset)
+	assert.Error(t, err, "function image cannot run without container")
+
+	s = &Service{Namespace: fake.Namespace}
+	err = s.RunLocal(manifest, LocalRun{Function: "missing", EnvFile: envFile}, clientset)
+	assert.EqualError(t, err, `function "missing" not found in "`+manifest+`"`)
+	assert.Len(t, commands, 2)
+}
+
+func TestReadEnvFile(t *testing.T) {
+	f, err := ioutil.TempFile("", "tm-env")
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+	_, err = f.WriteString("A=1\n\n# comment\nexport B = two words\nC='single'\nD=\"double\\n\"\nE=x=y\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	vars, err := readEnvFile(f.Name())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"A": "1", "B": "two words", "C": "single", "D": "double\n", "E": "x=y"}, vars)
+
+	require.NoError(t, ioutil.WriteFile(f.Name(), []byte("A=1\nINVALID\n"), 0644))
+	_, err = readEnvFile(f.Name())
+	assert.EqualError(t, err, f.Name()+":2: expected NAME=VALUE")
+}
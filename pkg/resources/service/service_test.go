@@ -24,13 +24,21 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/client/fake"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
 )
 
 // timeout in seconds to check that resulting service is reachable
 const dialTimeout = 10 * time.Second
 
 func TestDeployAndDelete(t *testing.T) {
+	if testing.Short() {
+		t.Skip("end-to-end test requires k8s cluster")
+	}
 	namespace := "test-namespace"
 	if ns, ok := os.LookupEnv("NAMESPACE"); ok {
 		namespace = ns
@@ -80,8 +88,9 @@ func TestDeployAndDelete(t *testing.T) {
 
 	client.Dry = false
 	client.Wait = true
+	defer func() { client.Wait = false }()
 	serviceClient, err := client.NewClient(client.ConfigPath(""))
-	assert.NoError(t, err)
+	require.NoError(t, err)
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -117,29 +126,21 @@ func TestDeployAndDelete(t *testing.T) {
 }
 
 func TestList(t *testing.T) {
-	namespace := "test-namespace"
-	if ns, ok := os.LookupEnv("NAMESPACE"); ok {
-		namespace = ns
-	}
-	serviceClient, err := client.NewClient(client.ConfigPath(""))
-	assert.NoError(t, err)
+	clientset := fake.NewConfigSet(&servingv1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: fake.Namespace},
+	})
 
-	s := &Service{Name: "foo", Namespace: namespace}
+	s := &Service{Name: "foo", Namespace: fake.Namespace}
 
-	_, err = s.List(&serviceClient)
+	list, err := s.List(clientset)
 	assert.NoError(t, err)
+	assert.Len(t, list.Items, 1)
 }
 
 func TestGet(t *testing.T) {
-	namespace := "test-namespace"
-	if ns, ok := os.LookupEnv("NAMESPACE"); ok {
-		namespace = ns
-	}
-	serviceClient, err := client.NewClient(client.ConfigPath(""))
-	assert.NoError(t, err)
+	clientset := fake.NewConfigSet()
 
-	s := &Service{Name: "foo", Namespace: namespace}
-	result, err := s.Get(&serviceClient)
-	assert.Error(t, err)
-	assert.Equal(t, "", result.Name)
+	s := &Service{Name: "foo", Namespace: fake.Namespace}
+	_, err := s.Get(clientset)
+	assert.True(t, k8serrors.IsNotFound(err))
 }
@@ -0,0 +1,528 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	githubv1alpha1 "knative.dev/eventing-contrib/github/pkg/apis/sources/v1alpha1"
+	sourcesv1alpha2 "knative.dev/eventing/pkg/apis/sources/v1alpha2"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	duckv1alpha1 "knative.dev/pkg/apis/duck/v1alpha1"
+	"knative.dev/pkg/kmeta"
+	"knative.dev/pkg/tracker"
+
+	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/file"
+)
+
+// Event source kinds supported in function definition
+const (
+	sourceAPIServer   = "apiserver"
+	sourceSinkBinding = "sinkbinding"
+	sourceContainer   = "container"
+	sourceGitHub      = "github"
+)
+
+// sourceKind returns the kind of event source that is set in definition
+func sourceKind(source file.Source) (string, error) {
+	var kinds []string
+	if source.APIServer != nil {
+		kinds = append(kinds, sourceAPIServer)
+	}
+	if source.SinkBinding != nil {
+		kinds = append(kinds, sourceSinkBinding)
+	}
+	if source.Container != nil {
+		kinds = append(kinds, sourceContainer)
+	}
+	if source.GitHub != nil {
+		kinds = append(kinds, sourceGitHub)
+	}
+	switch len(kinds) {
+	case 0:
+		return "", fmt.Errorf("source kind is not set")
+	case 1:
+		return kinds[0], nil
+	}
+	return "", fmt.Errorf("only one source kind can be set, got %s", strings.Join(kinds, ", "))
+}
+
+// validateSources checks that event sources have required fields set
+func (s *Service) validateSources() error {
+	for i, source := range s.Sources {
+		kind, err := sourceKind(source)
+		if err != nil {
+			return fmt.Errorf("source %d: %s", i, err)
+		}
+		if err := validateSource(kind, source); err != nil {
+			return fmt.Errorf("%s source %d: %s", kind, i, err)
+		}
+	}
+	return nil
+}
+
+func validateSource(kind string, source file.Source) error {
+	switch kind {
+	case sourceAPIServer:
+		if len(source.APIServer.Resources) == 0 {
+			return fmt.Errorf("resources list is empty")
+		}
+		for _, res := range source.APIServer.Resources {
+			if res.Kind == "" {
+				return fmt.Errorf("resource kind is required")
+			}
+			if res.Name != "" {
+				return fmt.Errorf("resources can be selected by labels only")
+			}
+		}
+		switch source.APIServer.Mode {
+		case "", sourcesv1alpha2.ReferenceMode, sourcesv1alpha2.ResourceMode:
+		default:
+			return fmt.Errorf("unknown mode %q", source.APIServer.Mode)
+		}
+	case sourceSinkBinding:
+		subject := source.SinkBinding.Subject
+		if subject.Kind == "" {
+			return fmt.Errorf("subject kind is required")
+		}
+		if (subject.Name == "") == (len(subject.Labels) == 0) {
+			return fmt.Errorf("subject must be selected either by name or by labels")
+		}
+	case sourceContainer:
+		if source.Container.Image == "" {
+			return fmt.Errorf("image is required")
+		}
+	case sourceGitHub:
+		if source.GitHub.Repository == "" {
+			return fmt.Errorf("repository is required")
+		}
+		if _, err := secretKeyRef(source.GitHub.AccessToken); err != nil {
+			return fmt.Errorf("access-token: %s", err)
+		}
+		if _, err := secretKeyRef(source.GitHub.SecretToken); err != nil {
+			return fmt.Errorf("secret-token: %s", err)
+		}
+	}
+	return nil
+}
+
+// secretKeyRef parses "secret-name:key" reference
+func secretKeyRef(ref string) (*corev1.SecretKeySelector, error) {
+	parts := strings.SplitN(ref, ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("secret reference %q must be in \"secret-name:key\" format", ref)
+	}
+	return &corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: parts[0]},
+		Key:                  parts[1],
+	}, nil
+}
+
+func labelSelector(labels map[string]string) *metav1.LabelSelector {
+	if len(labels) == 0 {
+		return nil
+	}
+	return &metav1.LabelSelector{MatchLabels: labels}
+}
+
+// sourceMeta returns common metadata of the event sources owned by service
+func (s *Service) sourceMeta() metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		GenerateName: s.Name + "-",
+		Namespace:    s.Namespace,
+		Labels: map[string]string{
+			serviceLabelKey: s.Name,
+		},
+	}
+}
+
+// sourceSpec returns source spec with the service as events sink
+func (s *Service) sourceSpec() duckv1.SourceSpec {
+	return duckv1.SourceSpec{
+		Sink: duckv1.Destination{
+			Ref: &duckv1.KReference{
+				APIVersion: "serving.knative.dev/v1",
+				Kind:       "Service",
+				Name:       s.Name,
+				Namespace:  s.Namespace,
+			},
+		},
+	}
+}
+
+func (s *Service) apiServerSource(source *file.APIServerSource) *sourcesv1alpha2.ApiServerSource {
+	var resources []sourcesv1alpha2.APIVersionKindSelector
+	for _, res := range source.Resources {
+		apiVersion := res.APIVersion
+		if apiVersion == "" {
+			apiVersion = "v1"
+		}
+		resources = append(resources, sourcesv1alpha2.APIVersionKindSelector{
+			APIVersion:    apiVersion,
+			Kind:          res.Kind,
+			LabelSelector: labelSelector(res.Labels),
+		})
+	}
+	mode := source.Mode
+	if mode == "" {
+		mode = sourcesv1alpha2.ReferenceMode
+	}
+	return &sourcesv1alpha2.ApiServerSource{
+		ObjectMeta: s.sourceMeta(),
+		Spec: sourcesv1alpha2.ApiServerSourceSpec{
+			SourceSpec:         s.sourceSpec(),
+			Resources:          resources,
+			EventMode:          mode,
+			ServiceAccountName: source.ServiceAccount,
+		},
+	}
+}
+
+func (s *Service) sinkBinding(source *file.SinkBindingSource) *sourcesv1alpha2.SinkBinding {
+	apiVersion := source.Subject.APIVersion
+	if apiVersion == "" {
+		apiVersion = "apps/v1"
+	}
+	return &sourcesv1alpha2.SinkBinding{
+		ObjectMeta: s.sourceMeta(),
+		Spec: sourcesv1alpha2.SinkBindingSpec{
+			SourceSpec: s.sourceSpec(),
+			BindingSpec: duckv1alpha1.BindingSpec{
+				Subject: tracker.Reference{
+					APIVersion: apiVersion,
+					Kind:       source.Subject.Kind,
+					Namespace:  s.Namespace,
+					Name:       source.Subject.Name,
+					Selector:   labelSelector(source.Subject.Labels),
+				},
+			},
+		},
+	}
+}
+
+func (s *Service) containerSource(source *file.ContainerSource) *sourcesv1alpha2.ContainerSource {
+	var env []corev1.EnvVar
+	for k, v := range source.Environment {
+		env = append(env, corev1.EnvVar{Name: k, Value: v})
+	}
+	sort.Slice(env, func(i, j int) bool {
+		return env[i].Name < env[j].Name
+	})
+	return &sourcesv1alpha2.ContainerSource{
+		ObjectMeta: s.sourceMeta(),
+		Spec: sourcesv1alpha2.ContainerSourceSpec{
+			SourceSpec: s.sourceSpec(),
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:  "source",
+							Image: source.Image,
+							Args:  source.Args,
+							Env:   env,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// gitHubSource composes GitHubSource object. Source must be validated before the call.
+func (s *Service) gitHubSource(source *file.GitHubSource) *githubv1alpha1.GitHubSource {
+	accessToken, _ := secretKeyRef(source.AccessToken)
+	secretToken, _ := secretKeyRef(source.SecretToken)
+	return &githubv1alpha1.GitHubSource{
+		ObjectMeta: s.sourceMeta(),
+		Spec: githubv1alpha1.GitHubSourceSpec{
+			OwnerAndRepository: source.Repository,
+			EventTypes:         source.EventTypes,
+			AccessToken:        githubv1alpha1.SecretValueFromSource{SecretKeyRef: accessToken},
+			SecretToken:        githubv1alpha1.SecretValueFromSource{SecretKeyRef: secretToken},
+			GitHubAPIURL:       source.APIURL,
+			SourceSpec:         s.sourceSpec(),
+		},
+	}
+}
+
+// syncSources replaces service event sources with the ones from the sources list.
+// Sources that have not changed are kept as is to avoid needless recreation,
+// e.g. GitHubSource would re-register repository webhook.
+func (s *Service) syncSources(owner kmeta.OwnerRefable, clientset *client.ConfigSet) {
+	for _, source := range s.Sources {
+		if _, err := sourceKind(source); err != nil {
+			clientset.Log.Errorf("Failed to create event source: %v", err)
+		}
+	}
+	desired, err := s.desiredSources()
+	if err != nil {
+		clientset.Log.Errorf("Failed to compose event sources: %v", err)
+		return
+	}
+	existing, err := s.existingSources(clientset)
+	if err != nil {
+		clientset.Log.Warnf("Failed to list event sources: %v", err)
+	}
+	stale, missing := matchSources(existing, desired)
+	for _, source := range stale {
+		clientset.Log.Infof("Removing %s event source %s", source.kind, source.name)
+		if err := s.deleteSource(source.kind, source.name, clientset); err != nil && !k8serrors.IsNotFound(err) {
+			clientset.Log.Warnf("Failed to remove %s event source %s: %v", source.kind, source.name, err)
+		}
+	}
+	ownerRefs := []metav1.OwnerReference{*kmeta.NewControllerRef(owner)}
+	for _, source := range missing {
+		clientset.Log.Infof("Creating %s event source", source.kind)
+		if err := s.createSource(source.kind, source.source, ownerRefs, clientset); err != nil {
+			clientset.Log.Errorf("Failed to create %s event source: %v", source.kind, err)
+		}
+	}
+}
+
+func (s *Service) createSource(kind string, source file.Source, ownerRefs []metav1.OwnerReference, clientset *client.ConfigSet) error {
+	var err error
+	switch kind {
+	case sourceAPIServer:
+		object := s.apiServerSource(source.APIServer)
+		object.OwnerReferences = ownerRefs
+		_, err = clientset.Eventing.SourcesV1alpha2().ApiServerSources(s.Namespace).Create(object)
+	case sourceSinkBinding:
+		object := s.sinkBinding(source.SinkBinding)
+		object.OwnerReferences = ownerRefs
+		_, err = clientset.Eventing.SourcesV1alpha2().SinkBindings(s.Namespace).Create(object)
+	case sourceContainer:
+		object := s.containerSource(source.Container)
+		object.OwnerReferences = ownerRefs
+		_, err = clientset.Eventing.SourcesV1alpha2().ContainerSources(s.Namespace).Create(object)
+	case sourceGitHub:
+		object := s.gitHubSource(source.GitHub)
+		object.OwnerReferences = ownerRefs
+		_, err = clientset.GithubSource.SourcesV1alpha1().GitHubSources(s.Namespace).Create(object)
+	}
+	return err
+}
+
+func (s *Service) deleteSource(kind, name string, clientset *client.ConfigSet) error {
+	deleteOptions := &metav1.DeleteOptions{}
+	switch kind {
+	case sourceAPIServer:
+		return clientset.Eventing.SourcesV1alpha2().ApiServerSources(s.Namespace).Delete(name, deleteOptions)
+	case sourceSinkBinding:
+		return clientset.Eventing.SourcesV1alpha2().SinkBindings(s.Namespace).Delete(name, deleteOptions)
+	case sourceContainer:
+		return clientset.Eventing.SourcesV1alpha2().ContainerSources(s.Namespace).Delete(name, deleteOptions)
+	case sourceGitHub:
+		return clientset.GithubSource.SourcesV1alpha1().GitHubSources(s.Namespace).Delete(name, deleteOptions)
+	}
+	return fmt.Errorf("unknown source kind %q", kind)
+}
+
+// ownedSource is the event source with flattened spec used to find the changes
+type ownedSource struct {
+	kind    string
+	name    string
+	summary string
+	spec    map[string]string
+	// source is the definition of desired event source
+	source file.Source
+}
+
+// source spec fields that are fully managed by tm, keys that are missing
+// in the desired spec are reported as removed
+var ownedSourceFields = []string{
+	"eventTypes",
+	"githubAPIURL",
+	"resources",
+	"subject.selector",
+	"template.spec.containers[0].args",
+	"template.spec.containers[0].env",
+}
+
+func sourceTracked(path string) bool {
+	return !strings.HasSuffix(path, "creationTimestamp")
+}
+
+func sourceOwned(path string) bool {
+	for _, field := range ownedSourceFields {
+		if strings.HasPrefix(path, field) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSources pairs existing sources with the equal desired ones and returns
+// existing sources that have no pair and desired sources that must be created
+func matchSources(existing, desired []ownedSource) ([]ownedSource, []ownedSource) {
+	matched := make([]bool, len(existing))
+	var missing []ownedSource
+	for _, d := range desired {
+		found := false
+		for i, e := range existing {
+			if matched[i] || e.kind != d.kind {
+				continue
+			}
+			if len(diffFields(e.spec, d.spec, sourceTracked, sourceOwned)) == 0 {
+				matched[i], found = true, true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, d)
+		}
+	}
+	var stale []ownedSource
+	for i, e := range existing {
+		if !matched[i] {
+			stale = append(stale, e)
+		}
+	}
+	return stale, missing
+}
+
+func newOwnedSource(kind, name, target string, spec interface{}) (ownedSource, error) {
+	fields, err := flatten(spec)
+	if err != nil {
+		return ownedSource{}, err
+	}
+	return ownedSource{
+		kind:    kind,
+		name:    name,
+		summary: sourceSummary(kind, target),
+		spec:    fields,
+	}, nil
+}
+
+// sourceSummary returns short description of the source used in deployment plan
+func sourceSummary(kind, target string) string {
+	return fmt.Sprintf("%s %q", kind, target)
+}
+
+func resourceKinds(resources []sourcesv1alpha2.APIVersionKindSelector) string {
+	var kinds []string
+	for _, res := range resources {
+		kinds = append(kinds, res.Kind)
+	}
+	return strings.Join(kinds, ",")
+}
+
+func subjectName(subject tracker.Reference) string {
+	if subject.Name != "" {
+		return subject.Kind + "/" + subject.Name
+	}
+	return subject.Kind
+}
+
+func containerImage(spec sourcesv1alpha2.ContainerSourceSpec) string {
+	if len(spec.Template.Spec.Containers) == 0 {
+		return ""
+	}
+	return spec.Template.Spec.Containers[0].Image
+}
+
+// desiredSources returns the event sources from function definition
+func (s *Service) desiredSources() ([]ownedSource, error) {
+	var sources []ownedSource
+	for _, source := range s.Sources {
+		kind, err := sourceKind(source)
+		if err != nil {
+			continue
+		}
+		var desired ownedSource
+		switch kind {
+		case sourceAPIServer:
+			spec := s.apiServerSource(source.APIServer).Spec
+			desired, err = newOwnedSource(kind, "", resourceKinds(spec.Resources), spec)
+		case sourceSinkBinding:
+			spec := s.sinkBinding(source.SinkBinding).Spec
+			desired, err = newOwnedSource(kind, "", subjectName(spec.Subject), spec)
+		case sourceContainer:
+			spec := s.containerSource(source.Container).Spec
+			desired, err = newOwnedSource(kind, "", containerImage(spec), spec)
+		case sourceGitHub:
+			spec := s.gitHubSource(source.GitHub).Spec
+			desired, err = newOwnedSource(kind, "", spec.OwnerAndRepository, spec)
+		}
+		if err != nil {
+			return nil, err
+		}
+		desired.source = source
+		sources = append(sources, desired)
+	}
+	return sources, nil
+}
+
+// existingSources returns the event sources owned by the service
+func (s *Service) existingSources(clientset *client.ConfigSet) ([]ownedSource, error) {
+	listOptions := metav1.ListOptions{
+		LabelSelector: serviceLabelKey + "=" + s.Name,
+	}
+	var sources []ownedSource
+	add := func(kind, name, target string, spec interface{}) error {
+		source, err := newOwnedSource(kind, name, target, spec)
+		if err != nil {
+			return err
+		}
+		sources = append(sources, source)
+		return nil
+	}
+	apiServerSources, err := clientset.Eventing.SourcesV1alpha2().ApiServerSources(s.Namespace).List(listOptions)
+	if err != nil {
+		return nil, err
+	}
+	for _, source := range apiServerSources.Items {
+		if err := add(sourceAPIServer, source.Name, resourceKinds(source.Spec.Resources), source.Spec); err != nil {
+			return nil, err
+		}
+	}
+	sinkBindings, err := clientset.Eventing.SourcesV1alpha2().SinkBindings(s.Namespace).List(listOptions)
+	if err != nil {
+		return nil, err
+	}
+	for _, source := range sinkBindings.Items {
+		if err := add(sourceSinkBinding, source.Name, subjectName(source.Spec.Subject), source.Spec); err != nil {
+			return nil, err
+		}
+	}
+	containerSources, err := clientset.Eventing.SourcesV1alpha2().ContainerSources(s.Namespace).List(listOptions)
+	if err != nil {
+		return nil, err
+	}
+	for _, source := range containerSources.Items {
+		if err := add(sourceContainer, source.Name, containerImage(source.Spec), source.Spec); err != nil {
+			return nil, err
+		}
+	}
+	gitHubSources, err := clientset.GithubSource.SourcesV1alpha1().GitHubSources(s.Namespace).List(listOptions)
+	switch {
+	case k8serrors.IsNotFound(err):
+		// GitHubSource CRD is not installed, there are no existing sources
+		return sources, nil
+	case err != nil:
+		return nil, err
+	}
+	for _, source := range gitHubSources.Items {
+		if err := add(sourceGitHub, source.Name, source.Spec.OwnerAndRepository, source.Spec); err != nil {
+			return nil, err
+		}
+	}
+	return sources, nil
+}
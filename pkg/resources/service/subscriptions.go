@@ -0,0 +1,87 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"fmt"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	messagingv1beta1 "knative.dev/eventing/pkg/apis/messaging/v1beta1"
+	"knative.dev/pkg/kmeta"
+
+	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/file"
+	"github.com/triggermesh/tm/pkg/resources/subscription"
+)
+
+// channelSubscription composes subscription that delivers channel events to the service
+func (s *Service) channelSubscription(sub file.Subscription) (*messagingv1beta1.Subscription, error) {
+	cs := subscription.Subscription{
+		Namespace: s.Namespace,
+		Channel:   sub.Channel,
+		Reply:     sub.Reply,
+		Service:   s.Name,
+	}
+	object, err := cs.NewObject()
+	if err != nil {
+		return nil, err
+	}
+	object.GenerateName = s.Name + "-"
+	object.Labels = map[string]string{
+		serviceLabelKey: s.Name,
+	}
+	return object, nil
+}
+
+// syncSubscriptions replaces service channel subscriptions with the ones from the list
+func (s *Service) syncSubscriptions(owner kmeta.OwnerRefable, clientset *client.ConfigSet) {
+	if err := s.removeSubscriptions(clientset); err != nil {
+		clientset.Log.Warnf("Failed to remove subscriptions: %v", err)
+	}
+	for _, sub := range s.Subscriptions {
+		cs, err := s.channelSubscription(sub)
+		if err == nil {
+			subscription.ResolveChannelRefs(cs, clientset)
+			cs.OwnerReferences = []metav1.OwnerReference{*kmeta.NewControllerRef(owner)}
+			clientset.Log.Infof("Subscribing to %q channel", sub.Channel)
+			err = s.createSubscription(cs, clientset)
+		}
+		if err != nil {
+			clientset.Log.Errorf("Failed to create subscription: %v", err)
+		}
+	}
+}
+
+func (s *Service) createSubscription(cs *messagingv1beta1.Subscription, clientset *client.ConfigSet) error {
+	_, err := clientset.Eventing.MessagingV1beta1().Subscriptions(cs.Namespace).Create(cs)
+	if err != nil {
+		return fmt.Errorf("cannot create Subscription to channel %q: %w", cs.Spec.Channel.Name, err)
+	}
+	return nil
+}
+
+func (s *Service) removeSubscriptions(clientset *client.ConfigSet) error {
+	err := clientset.Eventing.MessagingV1beta1().Subscriptions(s.Namespace).DeleteCollection(&metav1.DeleteOptions{}, metav1.ListOptions{
+		LabelSelector: serviceLabelKey + "=" + s.Name,
+	})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("cannot remove owned Subscriptions: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,260 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
+
+	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/file"
+)
+
+const (
+	// latestRevision is a traffic target placeholder
+	// for the latest ready revision of the service
+	latestRevision = "@latest"
+
+	canaryTag = "candidate"
+	stableTag = "current"
+
+	defaultCanaryInterval = time.Minute
+)
+
+// ParseTraffic converts "revision=percent" and "revision=tag" command line
+// arguments into the list of traffic targets
+func ParseTraffic(split, tags []string) ([]file.Traffic, error) {
+	var traffic []file.Traffic
+	index := make(map[string]int)
+	for _, v := range split {
+		t := strings.SplitN(v, "=", 2)
+		if len(t) != 2 {
+			return nil, fmt.Errorf("malformed traffic target %q, expected revision=percent", v)
+		}
+		percent, err := strconv.Atoi(t[1])
+		if err != nil {
+			return nil, fmt.Errorf("malformed traffic percent %q: %w", t[1], err)
+		}
+		index[t[0]] = len(traffic)
+		traffic = append(traffic, file.Traffic{
+			Revision: t[0],
+			Percent:  percent,
+		})
+	}
+	for _, v := range tags {
+		t := strings.SplitN(v, "=", 2)
+		if len(t) != 2 {
+			return nil, fmt.Errorf("malformed revision tag %q, expected revision=tag", v)
+		}
+		if i, ok := index[t[0]]; ok && traffic[i].Tag == "" {
+			traffic[i].Tag = t[1]
+			continue
+		}
+		traffic = append(traffic, file.Traffic{
+			Revision: t[0],
+			Tag:      t[1],
+		})
+	}
+	return traffic, nil
+}
+
+// trafficTargets validates requested traffic split and converts it
+// into knative route traffic targets. Targets without percents only tag
+// revisions and must be merged with the existing traffic.
+func (s *Service) trafficTargets() ([]servingv1.TrafficTarget, error) {
+	if len(s.Traffic) == 0 {
+		return nil, nil
+	}
+	if len(s.Canary.Steps) != 0 {
+		return nil, errors.New("traffic split and canary rollout cannot be used together")
+	}
+	var total int
+	targets := []servingv1.TrafficTarget{}
+	for _, t := range s.Traffic {
+		if t.Percent < 0 || t.Percent > 100 {
+			return nil, fmt.Errorf("revision %q traffic percent must be in 0-100 range", t.Revision)
+		}
+		total += t.Percent
+		targets = append(targets, trafficTarget(t.Revision, t.Tag, t.Percent))
+	}
+	if total != 100 && !s.tagsOnly() {
+		return nil, fmt.Errorf("traffic percents sum is %d, must be 100", total)
+	}
+	return targets, nil
+}
+
+// tagsOnly returns true if requested traffic targets only tag revisions
+// without changing traffic split
+func (s *Service) tagsOnly() bool {
+	if len(s.Traffic) == 0 {
+		return false
+	}
+	for _, t := range s.Traffic {
+		if t.Percent != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeTags applies revision tags to the existing traffic targets. Tags are moved
+// from the revisions they were previously assigned to, revisions that don't receive
+// traffic are added as zero percent targets. Empty traffic routes everything to the latest revision.
+func mergeTags(traffic, tags []servingv1.TrafficTarget) []servingv1.TrafficTarget {
+	merged := []servingv1.TrafficTarget{}
+	for _, t := range traffic {
+		merged = append(merged, *t.DeepCopy())
+	}
+	if len(merged) == 0 {
+		merged = append(merged, trafficTarget(latestRevision, "", 100))
+	}
+	for _, tag := range tags {
+		for i := range merged {
+			if merged[i].Tag == tag.Tag {
+				merged[i].Tag = ""
+			}
+		}
+		tagged := false
+		for i := range merged {
+			if merged[i].Tag == "" && sameRevision(merged[i], tag) {
+				merged[i].Tag = tag.Tag
+				tagged = true
+				break
+			}
+		}
+		if !tagged {
+			merged = append(merged, tag)
+		}
+	}
+	// targets that lost their tags and don't receive traffic are not needed anymore
+	result := merged[:0]
+	for _, t := range merged {
+		if t.Tag == "" && t.Percent != nil && *t.Percent == 0 {
+			continue
+		}
+		result = append(result, t)
+	}
+	return result
+}
+
+func sameRevision(a, b servingv1.TrafficTarget) bool {
+	latestA := a.LatestRevision != nil && *a.LatestRevision
+	latestB := b.LatestRevision != nil && *b.LatestRevision
+	if latestA || latestB {
+		return latestA == latestB
+	}
+	return a.RevisionName == b.RevisionName
+}
+
+func trafficTarget(revision, tag string, percent int) servingv1.TrafficTarget {
+	p := int64(percent)
+	latest := revision == "" || revision == latestRevision
+	target := servingv1.TrafficTarget{
+		Tag:            tag,
+		Percent:        &p,
+		LatestRevision: &latest,
+	}
+	if !latest {
+		target.RevisionName = revision
+	}
+	return target
+}
+
+func (s *Service) validateCanary() error {
+	var previous int
+	for _, step := range s.Canary.Steps {
+		if step <= previous || step > 100 {
+			return fmt.Errorf("canary steps must be increasing percents in 1-100 range, got %v", s.Canary.Steps)
+		}
+		previous = step
+	}
+	if s.Canary.Interval == "" {
+		return nil
+	}
+	if _, err := time.ParseDuration(s.Canary.Interval); err != nil {
+		return fmt.Errorf("canary interval: %w", err)
+	}
+	return nil
+}
+
+// canaryTargets splits traffic between stable revision and the latest one
+func canaryTargets(stable string, percent int) []servingv1.TrafficTarget {
+	if percent == 100 {
+		return []servingv1.TrafficTarget{trafficTarget(latestRevision, "", 100)}
+	}
+	return []servingv1.TrafficTarget{
+		trafficTarget(stable, stableTag, 100-percent),
+		trafficTarget(latestRevision, canaryTag, percent),
+	}
+}
+
+// stableRevision returns latest ready revision of existing service
+func (s *Service) stableRevision(clientset *client.ConfigSet) string {
+	service, err := s.Get(clientset)
+	if err != nil {
+		return ""
+	}
+	return service.Status.LatestReadyRevisionName
+}
+
+// rollout gradually shifts service traffic from stable revision to the latest one
+// according to canary steps. Service readiness is verified after each step.
+func (s *Service) rollout(stable string, clientset *client.ConfigSet) (string, error) {
+	interval := defaultCanaryInterval
+	if s.Canary.Interval != "" {
+		interval, _ = time.ParseDuration(s.Canary.Interval)
+	}
+	steps := append([]int{}, s.Canary.Steps...)
+	if steps[len(steps)-1] != 100 {
+		steps = append(steps, 100)
+	}
+	var url string
+	for i, percent := range steps {
+		if i != 0 {
+			clientset.Log.Infof("Waiting %s before the next canary step", interval)
+			time.Sleep(interval)
+			if err := s.updateTraffic(canaryTargets(stable, percent), clientset); err != nil {
+				return "", fmt.Errorf("updating traffic: %w", err)
+			}
+		}
+		clientset.Log.Infof("Routing %d%% of %q traffic to the latest revision", percent, s.Name)
+		var err error
+		if url, err = s.wait(clientset); err != nil {
+			clientset.Log.Warnf("Canary step failed, routing traffic back to %q", stable)
+			if err := s.updateTraffic(canaryTargets(stable, 0), clientset); err != nil {
+				clientset.Log.Errorf("Failed to restore traffic: %v", err)
+			}
+			return "", err
+		}
+	}
+	return url, nil
+}
+
+// updateTraffic replaces traffic block of existing service
+func (s *Service) updateTraffic(traffic []servingv1.TrafficTarget, clientset *client.ConfigSet) error {
+	service, err := clientset.Serving.ServingV1().Services(s.Namespace).Get(s.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	service.Spec.Traffic = traffic
+	_, err = clientset.Serving.ServingV1().Services(s.Namespace).Update(service)
+	return err
+}
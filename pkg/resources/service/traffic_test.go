@@ -0,0 +1,221 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
+
+	"github.com/triggermesh/tm/pkg/client/fake"
+	"github.com/triggermesh/tm/pkg/file"
+)
+
+func TestParseTraffic(t *testing.T) {
+	testCases := []struct {
+		name    string
+		split   []string
+		tags    []string
+		result  []file.Traffic
+		wantErr bool
+	}{
+		{
+			name:  "split with tags",
+			split: []string{"foo-abcde=90", "@latest=10"},
+			tags:  []string{"@latest=candidate", "foo-xyz=old"},
+			result: []file.Traffic{
+				{Revision: "foo-abcde", Percent: 90},
+				{Revision: "@latest", Percent: 10, Tag: "candidate"},
+				{Revision: "foo-xyz", Tag: "old"},
+			},
+		}, {
+			name:    "malformed percent",
+			split:   []string{"foo-abcde=ninety"},
+			wantErr: true,
+		}, {
+			name:    "malformed tag",
+			tags:    []string{"candidate"},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			traffic, err := ParseTraffic(tc.split, tc.tags)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.result, traffic)
+		})
+	}
+}
+
+func TestTrafficTargets(t *testing.T) {
+	testCases := []struct {
+		name    string
+		service Service
+		targets int
+		wantErr bool
+	}{
+		{
+			name:    "no traffic block",
+			service: Service{},
+		}, {
+			name: "valid split",
+			service: Service{
+				Traffic: []file.Traffic{
+					{Revision: "foo-abcde", Percent: 75},
+					{Revision: "@latest", Percent: 25},
+				},
+			},
+			targets: 2,
+		}, {
+			name: "wrong sum",
+			service: Service{
+				Traffic: []file.Traffic{
+					{Revision: "foo-abcde", Percent: 75},
+				},
+			},
+			wantErr: true,
+		}, {
+			name: "tags only",
+			service: Service{
+				Traffic: []file.Traffic{
+					{Revision: "foo-abcde", Tag: "candidate"},
+				},
+			},
+			targets: 1,
+		}, {
+			name: "split with canary",
+			service: Service{
+				Traffic: []file.Traffic{
+					{Revision: "@latest", Percent: 100},
+				},
+				Canary: file.Canary{Steps: []int{10}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			targets, err := tc.service.trafficTargets()
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Len(t, targets, tc.targets)
+		})
+	}
+}
+
+func TestMergeTags(t *testing.T) {
+	split := []servingv1.TrafficTarget{
+		trafficTarget("foo-abcde", "current", 90),
+		trafficTarget(latestRevision, "", 10),
+	}
+
+	merged := mergeTags(split, []servingv1.TrafficTarget{trafficTarget(latestRevision, "candidate", 0)})
+	assert.Equal(t, []servingv1.TrafficTarget{
+		trafficTarget("foo-abcde", "current", 90),
+		trafficTarget(latestRevision, "candidate", 10),
+	}, merged)
+
+	// tag is moved to another revision
+	merged = mergeTags(split, []servingv1.TrafficTarget{trafficTarget("foo-fghij", "current", 0)})
+	assert.Equal(t, []servingv1.TrafficTarget{
+		trafficTarget("foo-abcde", "", 90),
+		trafficTarget(latestRevision, "", 10),
+		trafficTarget("foo-fghij", "current", 0),
+	}, merged)
+
+	// service without traffic block routes everything to the latest revision
+	merged = mergeTags(nil, []servingv1.TrafficTarget{trafficTarget("foo-abcde", "candidate", 0)})
+	assert.Equal(t, []servingv1.TrafficTarget{
+		trafficTarget(latestRevision, "", 100),
+		trafficTarget("foo-abcde", "candidate", 0),
+	}, merged)
+}
+
+func TestFakeDeployTagsOnly(t *testing.T) {
+	existing := readyService("foo", "foo.example.com")
+	existing.Spec.Traffic = []servingv1.TrafficTarget{
+		trafficTarget("foo-abcde", "", 90),
+		trafficTarget(latestRevision, "", 10),
+	}
+	clientset := fake.NewConfigSet(existing)
+
+	traffic, err := ParseTraffic(nil, []string{"foo-abcde=stable"})
+	require.NoError(t, err)
+	s := &Service{
+		Name:            "foo",
+		Namespace:       fake.Namespace,
+		Source:          "gcr.io/google-samples/hello-app:1.0",
+		NoDigestResolve: true,
+		Traffic:         traffic,
+	}
+	_, err = s.Deploy(clientset)
+	require.NoError(t, err)
+
+	service, err := s.Get(clientset)
+	require.NoError(t, err)
+	assert.Equal(t, []servingv1.TrafficTarget{
+		trafficTarget("foo-abcde", "stable", 90),
+		trafficTarget(latestRevision, "", 10),
+	}, service.Spec.Traffic)
+}
+
+func TestFakeDeployCanaryWithoutWait(t *testing.T) {
+	existing := readyService("foo", "foo.example.com")
+	existing.Status.LatestReadyRevisionName = "foo-00001"
+	clientset := fake.NewConfigSet(existing)
+
+	s := &Service{
+		Name:            "foo",
+		Namespace:       fake.Namespace,
+		Source:          "gcr.io/google-samples/hello-app:2.0",
+		NoDigestResolve: true,
+		Canary:          file.Canary{Steps: []int{10, 50}, Interval: "1h"},
+	}
+	// rollout is not started without --wait, only the first split is set
+	output, err := s.Deploy(clientset)
+	require.NoError(t, err)
+	assert.Contains(t, output, "Deployment started")
+
+	service, err := s.Get(clientset)
+	require.NoError(t, err)
+	assert.Equal(t, canaryTargets("foo-00001", 10), service.Spec.Traffic)
+}
+
+func TestCanaryTargets(t *testing.T) {
+	targets := canaryTargets("foo-abcde", 20)
+	assert.Len(t, targets, 2)
+	assert.Equal(t, "foo-abcde", targets[0].RevisionName)
+	assert.Equal(t, int64(80), *targets[0].Percent)
+	assert.True(t, *targets[1].LatestRevision)
+	assert.Equal(t, int64(20), *targets[1].Percent)
+
+	targets = canaryTargets("foo-abcde", 100)
+	assert.Len(t, targets, 1)
+	assert.True(t, *targets[0].LatestRevision)
+
+	s := Service{Canary: file.Canary{Steps: []int{50, 10}}}
+	assert.Error(t, s.validateCanary())
+}
@@ -0,0 +1,93 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"fmt"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	eventingv1beta1 "knative.dev/eventing/pkg/apis/eventing/v1beta1"
+	"knative.dev/pkg/kmeta"
+
+	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/file"
+	"github.com/triggermesh/tm/pkg/resources/trigger"
+)
+
+// eventTrigger composes broker trigger that delivers function events to the service
+func (s *Service) eventTrigger(event file.Event) (*eventingv1beta1.Trigger, error) {
+	filter := make(map[string]string, len(event.Filter)+1)
+	for k, v := range event.Filter {
+		filter[k] = v
+	}
+	if event.Type != "" {
+		filter["type"] = event.Type
+	}
+	t := trigger.Trigger{
+		Namespace: s.Namespace,
+		Broker:    event.Broker,
+		Filter:    filter,
+		Service:   s.Name,
+	}
+	object, err := t.NewObject()
+	if err != nil {
+		return nil, err
+	}
+	object.GenerateName = s.Name + "-"
+	object.Labels = map[string]string{
+		serviceLabelKey: s.Name,
+	}
+	return object, nil
+}
+
+// syncTriggers replaces service triggers with the ones from the events list
+func (s *Service) syncTriggers(owner kmeta.OwnerRefable, clientset *client.ConfigSet) {
+	if err := s.removeTriggers(clientset); err != nil {
+		clientset.Log.Warnf("Failed to remove triggers: %v", err)
+	}
+	for _, event := range s.Triggers {
+		t, err := s.eventTrigger(event)
+		if err == nil {
+			t.OwnerReferences = []metav1.OwnerReference{*kmeta.NewControllerRef(owner)}
+			clientset.Log.Infof("Creating %q broker trigger", t.Spec.Broker)
+			err = s.createTrigger(t, clientset)
+		}
+		if err != nil {
+			clientset.Log.Errorf("Failed to create trigger: %v", err)
+		}
+	}
+}
+
+func (s *Service) createTrigger(t *eventingv1beta1.Trigger, clientset *client.ConfigSet) error {
+	_, err := clientset.Eventing.EventingV1beta1().Triggers(t.Namespace).Create(t)
+	if err != nil {
+		return fmt.Errorf("cannot create Trigger for broker %q: %w", t.Spec.Broker, err)
+	}
+	return nil
+}
+
+func (s *Service) removeTriggers(clientset *client.ConfigSet) error {
+	err := clientset.Eventing.EventingV1beta1().Triggers(s.Namespace).DeleteCollection(&metav1.DeleteOptions{}, metav1.ListOptions{
+		LabelSelector: serviceLabelKey + "=" + s.Name,
+	})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("cannot remove owned Triggers: %w", err)
+	}
+	return nil
+}
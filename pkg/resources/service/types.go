@@ -18,22 +18,42 @@ import "github.com/triggermesh/tm/pkg/file"
 
 // Service represents knative service structure
 type Service struct {
-	Annotations    map[string]string
-	BuildArgs      []string
-	BuildTimeout   string
-	BuildOnly      bool
-	Concurrency    int
-	Env            []string
-	EnvSecrets     []string
-	Labels         []string
-	Name           string
-	Namespace      string
-	PullPolicy     string
-	Revision       string
-	ResultImageTag string
+	Annotations     map[string]string
+	BuildArgs       []string
+	BuildTimeout    string
+	BuildOnly       bool
+	Concurrency     int
+	Env             []string
+	EnvSecrets      []string
+	ForceBuild      bool
+	Labels          []string
+	Limits          map[string]string
+	MaxScale        int
+	MinScale        int
+	Name            string
+	Namespace       string
+	NoDigestResolve bool
+	PullPolicy      string
+	QuietBuild      bool
+	Requests        map[string]string
+	Revision        string
+	ResultImageTag  string
+	// ServiceAccount is the name of service account that service pods run with
+	ServiceAccount string
+	// BuildServiceAccount is the name of service account used to build the image.
+	// Defaults to ServiceAccount
+	BuildServiceAccount string
 	// Originally knative/buildtemplate, but now also tekton/task
-	Runtime string
-	Source  string
+	Runtime           string
+	Source            string
+	Stage             string
+	Target            int
+	TargetUtilization int
 	// TODO: get rid of file package dependency
-	Schedule []file.Schedule
+	Schedule      []file.Schedule
+	Triggers      []file.Event
+	Subscriptions []file.Subscription
+	Sources       []file.Source
+	Traffic       []file.Traffic
+	Canary        file.Canary
 }
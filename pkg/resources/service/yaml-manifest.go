@@ -31,6 +31,9 @@ import (
 var Output io.Writer = os.Stdout
 var yamlFile = "serverless.yaml"
 
+// maximum depth of nested manifest includes, protects from include loops
+const maxIncludeDepth = 10
+
 type status struct {
 	Message string
 	Error   error
@@ -137,7 +140,10 @@ func (s *Service) ManifestToServices(YAML string) ([]Service, error) {
 	if YAML, err = getYAML(YAML); err != nil {
 		return nil, err
 	}
-	definition, err := file.ParseManifest(YAML)
+	if err := validateManifest(YAML, false); err != nil {
+		return nil, err
+	}
+	definition, err := file.ParseStageManifest(YAML, s.Stage)
 	if err != nil {
 		return nil, err
 	}
@@ -150,29 +156,128 @@ func (s *Service) ManifestToServices(YAML string) ([]Service, error) {
 	s.setupParentVars(definition)
 
 	functions := s.parseFunctions(definition.Functions, path.Dir(YAML))
-	includedFunctions, err := s.parseIncludes(definition.Include, path.Dir(YAML))
+	includedFunctions, err := s.parseIncludes(definition.Include, 0, path.Dir(YAML))
 	if err != nil {
 		return nil, err
 	}
 	return append(functions, includedFunctions...), nil
 }
 
-func (s *Service) parseIncludes(includes []string, workdir ...string) ([]Service, error) {
+// ValidateYAML checks manifest and the manifests it includes
+// and returns the list of found problems. Manifests are parsed with
+// the stage overrides and variables the same way as before deployment.
+func (s *Service) ValidateYAML(YAML string) ([]file.Problem, error) {
+	var err error
+	if YAML, err = getYAML(YAML); err != nil {
+		return nil, err
+	}
+	return s.validateFile(YAML, false, 0)
+}
+
+// validateFile checks manifest schema, resolves its variables
+// and recursively validates included manifests
+func (s *Service) validateFile(YAML string, included bool, depth int) ([]file.Problem, error) {
+	problems, includes, err := file.ValidateManifest(YAML, included)
+	if err != nil {
+		return nil, err
+	}
+	if len(problems) != 0 {
+		return problems, nil
+	}
+	report := func(line, column int, format string, args ...interface{}) {
+		problems = append(problems, file.Problem{
+			File:    YAML,
+			Line:    line,
+			Column:  column,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	definition, err := file.ParseStageManifest(YAML, s.Stage)
+	if err != nil {
+		report(1, 1, "%s", err)
+		return problems, nil
+	}
+	if !included {
+		if err := definition.Validate(); err != nil {
+			report(1, 1, "%s", err)
+		}
+	}
+
+	positions := make(map[string]file.Include, len(includes))
+	for _, include := range includes {
+		positions[include.Path] = include
+	}
+	for _, include := range definition.Include {
+		position := positions[include]
+		if depth >= maxIncludeDepth {
+			report(position.Line, position.Column, "include %q: includes nesting is too deep, check for include loops", include)
+			continue
+		}
+		// local includes are checked first to avoid remote lookups
+		includePath := path.Join(path.Dir(YAML), include)
+		if !file.IsLocal(includePath) && file.IsRemote(include) {
+			includePath = include
+		}
+		includeYAML, err := getYAML(includePath)
+		if err != nil {
+			report(position.Line, position.Column, "include %q: %s", include, err)
+			continue
+		}
+		includeProblems, err := s.validateFile(includeYAML, true, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		problems = append(problems, includeProblems...)
+	}
+	return problems, nil
+}
+
+// validateManifest returns error with the list of manifest problems, if any
+func validateManifest(YAML string, included bool) error {
+	problems, _, err := file.ValidateManifest(YAML, included)
+	if err != nil {
+		return err
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	var messages []string
+	for _, problem := range problems {
+		messages = append(messages, problem.String())
+	}
+	return fmt.Errorf("manifest is not valid:\n%s", strings.Join(messages, "\n"))
+}
+
+// parseIncludes returns functions of the included manifests and the manifests they include
+func (s *Service) parseIncludes(includes []string, depth int, workdir ...string) ([]Service, error) {
 	var services []Service
 	var definition file.Definition
 	for _, include := range includes {
-		if !file.IsRemote(include) && len(workdir) == 1 {
+		if depth >= maxIncludeDepth {
+			return []Service{}, fmt.Errorf("include %q: includes nesting is too deep, check for include loops", include)
+		}
+		// local includes are checked first to avoid remote lookups
+		if len(workdir) == 1 && (file.IsLocal(path.Join(workdir[0], include)) || !file.IsRemote(include)) {
 			include = path.Join(workdir[0], include)
 		}
 		YAML, err := getYAML(include)
 		if err != nil {
 			return []Service{}, err
 		}
-		definition, err = file.ParseManifest(YAML)
+		if err := validateManifest(YAML, true); err != nil {
+			return []Service{}, err
+		}
+		definition, err = file.ParseStageManifest(YAML, s.Stage)
 		if err != nil {
 			return []Service{}, err
 		}
 		services = append(services, s.parseFunctions(definition.Functions, path.Dir(YAML))...)
+		nested, err := s.parseIncludes(definition.Include, depth+1, path.Dir(YAML))
+		if err != nil {
+			return []Service{}, err
+		}
+		services = append(services, nested...)
 	}
 
 	return services, nil
@@ -185,7 +290,14 @@ func (s *Service) parseFunctions(functions map[string]file.Function, workdir ...
 		service.Name = fmt.Sprintf("%s-%s", s.Name, name)
 		service.Labels = append(service.Labels, "service:"+s.Name)
 		service.Schedule = function.Schedule
-		if !file.IsRemote(service.Source) && len(workdir) == 1 {
+		service.Triggers = function.Events
+		service.Subscriptions = function.Subscriptions
+		service.Sources = function.Sources
+		service.Traffic = function.Traffic
+		service.Canary = function.Canary
+		// relative local sources are resolved against the manifest directory,
+		// image names are left as is
+		if !file.IsRemote(service.Source) && len(workdir) == 1 && file.IsLocal(path.Join(workdir[0], service.Source)) {
 			service.Source = path.Join(workdir[0], service.Source)
 		}
 		services = append(services, service)
@@ -211,6 +323,9 @@ func (s *Service) setupParentVars(definition file.Definition) {
 	s.PullPolicy = definition.Provider.PullPolicy
 	s.Runtime = definition.Provider.Runtime
 	s.BuildTimeout = definition.Provider.Buildtimeout
+	if len(s.ServiceAccount) == 0 {
+		s.ServiceAccount = definition.Provider.ServiceAccount
+	}
 
 	if len(s.Namespace) == 0 {
 		s.Namespace = definition.Provider.Namespace
@@ -224,23 +339,30 @@ func (s *Service) setupParentVars(definition file.Definition) {
 	for k, v := range definition.Provider.Environment {
 		s.Env = append(s.Env, k+":"+v)
 	}
+	s.Requests = definition.Provider.Resources.Requests
+	s.Limits = definition.Provider.Resources.Limits
+	s.setScaling(definition.Provider.Scaling)
 }
 
 func (s *Service) serviceObject(function file.Function) Service {
 	service := Service{
-		Source:         function.Source,
-		Revision:       function.Revision,
-		Namespace:      s.Namespace,
-		Concurrency:    function.Concurrency,
-		Runtime:        function.Runtime,
-		Labels:         function.Labels,
-		PullPolicy:     s.PullPolicy,
-		ResultImageTag: "latest",
-		BuildArgs:      function.Buildargs,
-		BuildTimeout:   s.BuildTimeout,
-		Env:            s.Env,
-		Annotations:    make(map[string]string),
-		EnvSecrets:     append(s.EnvSecrets, function.EnvSecrets...),
+		Source:          function.Source,
+		Revision:        function.Revision,
+		Namespace:       s.Namespace,
+		Concurrency:     function.Concurrency,
+		Runtime:         function.Runtime,
+		Labels:          function.Labels,
+		PullPolicy:      s.PullPolicy,
+		QuietBuild:      s.QuietBuild,
+		ForceBuild:      s.ForceBuild,
+		NoDigestResolve: s.NoDigestResolve,
+		ResultImageTag:  "latest",
+		BuildArgs:       function.Buildargs,
+		BuildTimeout:    s.BuildTimeout,
+		ServiceAccount:  function.ServiceAccount,
+		Env:             s.Env,
+		Annotations:     make(map[string]string),
+		EnvSecrets:      append(s.EnvSecrets, function.EnvSecrets...),
 	}
 	// For back-compatibility with old "handler" field
 	if len(function.Handler) != 0 {
@@ -258,20 +380,80 @@ func (s *Service) serviceObject(function file.Function) Service {
 	if len(service.Runtime) == 0 {
 		service.Runtime = s.Runtime
 	}
+	if len(service.ServiceAccount) == 0 {
+		service.ServiceAccount = s.ServiceAccount
+	}
+	service.BuildServiceAccount = s.BuildServiceAccount
+	service.Requests = mergeResources(s.Requests, function.Resources.Requests)
+	service.Limits = mergeResources(s.Limits, function.Resources.Limits)
+	service.MinScale, service.MaxScale = s.MinScale, s.MaxScale
+	service.Target, service.TargetUtilization = s.Target, s.TargetUtilization
+	service.setScaling(function.Scaling)
 	if len(function.Description) != 0 {
 		service.Annotations["Description"] = fmt.Sprintf("%s\n%s", service.Annotations["Description"], function.Description)
 	}
 	return service
 }
 
+// setScaling overrides Service autoscaling parameters with the values set in manifest
+func (s *Service) setScaling(scaling file.Scaling) {
+	if scaling.MinScale != 0 {
+		s.MinScale = scaling.MinScale
+	}
+	if scaling.MaxScale != 0 {
+		s.MaxScale = scaling.MaxScale
+	}
+	if scaling.Target != 0 {
+		s.Target = scaling.Target
+	}
+	if scaling.TargetUtilization != 0 {
+		s.TargetUtilization = scaling.TargetUtilization
+	}
+}
+
+func mergeResources(provider, function map[string]string) map[string]string {
+	if len(provider) == 0 && len(function) == 0 {
+		return nil
+	}
+	res := make(map[string]string)
+	for k, v := range provider {
+		res[k] = v
+	}
+	for k, v := range function {
+		res[k] = v
+	}
+	return res
+}
+
 func (s *Service) removeOrphans(created []Service, clientset *client.ConfigSet) error {
-	list, err := clientset.Serving.ServingV1alpha1().Services(s.Namespace).List(metav1.ListOptions{
+	orphans, err := s.orphans(created, clientset)
+	if err != nil {
+		return err
+	}
+	for _, name := range orphans {
+		orphan := Service{
+			Name:      name,
+			Namespace: s.Namespace,
+		}
+		fmt.Fprintf(Output, "Removing orphaned function %s\n", orphan.Name)
+		if err = orphan.Delete(clientset); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// orphans returns names of existing services that belong
+// to the current manifest but are missing in created list
+func (s *Service) orphans(created []Service, clientset *client.ConfigSet) ([]string, error) {
+	list, err := clientset.Serving.ServingV1().Services(s.Namespace).List(metav1.ListOptions{
 		LabelSelector: "service=" + s.Name,
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	var orphans []string
 	for _, existing := range list.Items {
 		orphaned := true
 		for _, newService := range created {
@@ -281,17 +463,10 @@ func (s *Service) removeOrphans(created []Service, clientset *client.ConfigSet)
 			}
 		}
 		if orphaned {
-			orphan := Service{
-				Name:      existing.Name,
-				Namespace: existing.Namespace,
-			}
-			fmt.Fprintf(Output, "Removing orphaned function %s\n", orphan.Name)
-			if err = orphan.Delete(clientset); err != nil {
-				return err
-			}
+			orphans = append(orphans, existing.Name)
 		}
 	}
-	return nil
+	return orphans, nil
 }
 
 func getYAML(filepath string) (string, error) {
@@ -0,0 +1,106 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func manifestDir(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "tm-manifest")
+	require.NoError(t, err)
+	for name, content := range files {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+	return dir
+}
+
+func TestValidateYAMLStage(t *testing.T) {
+	dir := manifestDir(t, map[string]string{
+		"serverless.yaml": `service: foo
+functions:
+  bar:
+    source: gcr.io/foo/bar
+stages:
+  prod:
+    functions:
+      bar:
+        source: gcr.io/foo/bar:${env:TM_TEST_UNSET_VARIABLE}
+`,
+	})
+	defer os.RemoveAll(dir)
+	manifest := filepath.Join(dir, "serverless.yaml")
+
+	s := &Service{}
+	problems, err := s.ValidateYAML(manifest)
+	require.NoError(t, err)
+	assert.Empty(t, problems)
+
+	s.Stage = "prod"
+	problems, err = s.ValidateYAML(manifest)
+	require.NoError(t, err)
+	require.Len(t, problems, 1)
+	assert.Contains(t, problems[0].Message, "TM_TEST_UNSET_VARIABLE")
+	_, err = s.ManifestToServices(manifest)
+	assert.Error(t, err)
+
+	s.Stage = "staging"
+	problems, err = s.ValidateYAML(manifest)
+	require.NoError(t, err)
+	require.Len(t, problems, 1)
+	assert.Contains(t, problems[0].Message, `stage "staging" is not defined`)
+}
+
+func TestValidateYAMLNestedIncludes(t *testing.T) {
+	dir := manifestDir(t, map[string]string{
+		"serverless.yaml": "service: foo\ninclude:\n  - first.yaml\n",
+		"first.yaml":      "functions:\n  first:\n    source: https://gcr.io/foo/first\ninclude:\n  - second.yaml\n",
+		"second.yaml":     "functions:\n  second:\n    source: https://gcr.io/foo/second\n",
+		"loop.yaml":       "service: foo\ninclude:\n  - loop.yaml\n",
+	})
+	defer os.RemoveAll(dir)
+
+	s := &Service{}
+	problems, err := s.ValidateYAML(filepath.Join(dir, "serverless.yaml"))
+	require.NoError(t, err)
+	assert.Empty(t, problems)
+	services, err := s.ManifestToServices(filepath.Join(dir, "serverless.yaml"))
+	require.NoError(t, err)
+	var names []string
+	for _, service := range services {
+		names = append(names, service.Name)
+	}
+	assert.ElementsMatch(t, []string{"foo-first", "foo-second"}, names)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "second.yaml"), []byte("functions:\n  second:\n    sauce: gcr.io/foo/second\n"), 0644))
+	problems, err = s.ValidateYAML(filepath.Join(dir, "serverless.yaml"))
+	require.NoError(t, err)
+	require.Len(t, problems, 1)
+	assert.Equal(t, filepath.Join(dir, "second.yaml"), problems[0].File)
+	assert.Contains(t, problems[0].Message, `unknown field "sauce"`)
+
+	problems, err = s.ValidateYAML(filepath.Join(dir, "loop.yaml"))
+	require.NoError(t, err)
+	require.NotEmpty(t, problems)
+	assert.Contains(t, problems[0].Message, "includes nesting is too deep")
+	_, err = s.ManifestToServices(filepath.Join(dir, "loop.yaml"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,148 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package subscription
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ghodss/yaml"
+	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/resources/channel"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	messagingapi "knative.dev/eventing/pkg/apis/messaging/v1beta1"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+const (
+	channelKind       = "Channel"
+	channelAPIVersion = "messaging.knative.dev/v1beta1"
+)
+
+// Deploy knative channel subscription
+func (s *Subscription) Deploy(clientset *client.ConfigSet) error {
+	subscriptionObject, err := s.NewObject()
+	if err != nil {
+		return err
+	}
+	if !client.Dry {
+		ResolveChannelRefs(subscriptionObject, clientset)
+	}
+	if client.Dry {
+		res, err := yaml.Marshal(subscriptionObject)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s\n", res)
+		return nil
+	}
+	return s.createOrUpdate(subscriptionObject, clientset)
+}
+
+// NewObject returns subscription object with knative service as a subscriber
+func (s *Subscription) NewObject() (*messagingapi.Subscription, error) {
+	if s.Channel == "" {
+		return nil, errors.New("subscription channel is not set")
+	}
+	if s.Service == "" {
+		return nil, errors.New("subscription subscriber service is not set")
+	}
+	subscription := &messagingapi.Subscription{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Subscription",
+			APIVersion: "messaging.knative.dev/v1beta1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      s.Name,
+			Namespace: s.Namespace,
+		},
+		Spec: messagingapi.SubscriptionSpec{
+			Channel: corev1.ObjectReference{
+				APIVersion: channelAPIVersion,
+				Kind:       channelKind,
+				Name:       s.Channel,
+			},
+			Subscriber: &duckv1.Destination{
+				Ref: &duckv1.KReference{
+					APIVersion: "serving.knative.dev/v1",
+					Kind:       "Service",
+					Name:       s.Service,
+					Namespace:  s.Namespace,
+				},
+			},
+		},
+	}
+	if s.Reply != "" {
+		subscription.Spec.Reply = &duckv1.Destination{
+			Ref: &duckv1.KReference{
+				APIVersion: channelAPIVersion,
+				Kind:       channelKind,
+				Name:       s.Reply,
+				Namespace:  s.Namespace,
+			},
+		}
+	}
+	return subscription, nil
+}
+
+// ResolveChannelRefs updates channel and reply references kind with the kind
+// of the existing objects. InMemoryChannel is referenced if there is no channel
+// with the same name, the same way as channel Get does. References to the
+// channels that do not exist yet are left unchanged.
+func ResolveChannelRefs(subscription *messagingapi.Subscription, clientset *client.ConfigSet) {
+	if apiVersion, kind, ok := channelType(subscription.Spec.Channel.Name, subscription.Namespace, clientset); ok {
+		subscription.Spec.Channel.APIVersion = apiVersion
+		subscription.Spec.Channel.Kind = kind
+	}
+	if reply := subscription.Spec.Reply; reply != nil && reply.Ref != nil {
+		if apiVersion, kind, ok := channelType(reply.Ref.Name, subscription.Namespace, clientset); ok {
+			reply.Ref.APIVersion = apiVersion
+			reply.Ref.Kind = kind
+		}
+	}
+}
+
+func channelType(name, namespace string, clientset *client.ConfigSet) (string, string, bool) {
+	c := channel.Channel{Name: name, Namespace: namespace}
+	ch, err := c.Get(clientset)
+	if err != nil {
+		clientset.Log.Debugf("cannot get %q channel: %s", name, err)
+		return "", "", false
+	}
+	if ch.Kind == "" {
+		return channelAPIVersion, channelKind, true
+	}
+	return ch.APIVersion, ch.Kind, true
+}
+
+func (s *Subscription) createOrUpdate(subscriptionObject *messagingapi.Subscription, clientset *client.ConfigSet) error {
+	_, err := clientset.Eventing.MessagingV1beta1().Subscriptions(s.Namespace).Create(subscriptionObject)
+	if k8serrors.IsAlreadyExists(err) {
+		subscription, err := clientset.Eventing.MessagingV1beta1().Subscriptions(s.Namespace).Get(subscriptionObject.ObjectMeta.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if subscription.Spec.Channel.Name != subscriptionObject.Spec.Channel.Name {
+			return fmt.Errorf("subscription %q already exists for channel %q, channel cannot be changed", s.Name, subscription.Spec.Channel.Name)
+		}
+		subscriptionObject.ObjectMeta.ResourceVersion = subscription.GetResourceVersion()
+		subscriptionObject.ObjectMeta.Annotations = subscription.GetAnnotations()
+		_, err = clientset.Eventing.MessagingV1beta1().Subscriptions(s.Namespace).Update(subscriptionObject)
+		return err
+	}
+	return err
+}
@@ -0,0 +1,94 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package subscription
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/printer"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+	messagingapi "knative.dev/eventing/pkg/apis/messaging/v1beta1"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+// GetTable converts k8s list instance into printable object
+func (s *Subscription) GetTable(list *messagingapi.SubscriptionList) printer.Table {
+	table := printer.Table{
+		Headers: []string{
+			"Namespace",
+			"Name",
+			"Channel",
+			"Subscriber",
+			"Reply",
+			"Age",
+			"Ready",
+			"Reason",
+		},
+		Rows: make([][]string, 0, len(list.Items)),
+	}
+
+	for _, item := range list.Items {
+		table.Rows = append(table.Rows, s.row(&item))
+	}
+	return table
+}
+
+func (s *Subscription) row(item *messagingapi.Subscription) []string {
+	name := item.Name
+	namespace := item.Namespace
+	channel := item.Spec.Channel.Kind + "/" + item.Spec.Channel.Name
+	subscriber := destination(item.Spec.Subscriber)
+	reply := destination(item.Spec.Reply)
+	age := duration.HumanDuration(time.Since(item.GetCreationTimestamp().Time))
+	ready := fmt.Sprintf("%v", item.Status.IsReady())
+	readyCondition := item.Status.GetCondition(messagingapi.SubscriptionConditionReady)
+	reason := ""
+	if readyCondition != nil {
+		reason = readyCondition.Reason
+	}
+
+	row := []string{
+		namespace,
+		name,
+		channel,
+		subscriber,
+		reply,
+		age,
+		ready,
+		reason,
+	}
+
+	return row
+}
+
+func destination(d *duckv1.Destination) string {
+	switch {
+	case d == nil:
+		return ""
+	case d.Ref != nil:
+		return d.Ref.Kind + "/" + d.Ref.Name
+	case d.URI != nil:
+		return d.URI.String()
+	}
+	return ""
+}
+
+// List returns list of knative subscription objects
+func (s *Subscription) List(clientset *client.ConfigSet) (*messagingapi.SubscriptionList, error) {
+	return clientset.Eventing.MessagingV1beta1().Subscriptions(s.Namespace).List(metav1.ListOptions{})
+}
@@ -0,0 +1,84 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package subscription
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/triggermesh/tm/pkg/client/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	messagingapi "knative.dev/eventing/pkg/apis/messaging/v1beta1"
+)
+
+func TestFakeDeployGetListDelete(t *testing.T) {
+	clientset := fake.NewConfigSet()
+	s := &Subscription{
+		Name:      "foo",
+		Namespace: fake.Namespace,
+		Channel:   "events",
+	}
+	assert.Error(t, s.Deploy(clientset))
+
+	s.Service = "bar"
+	require.NoError(t, s.Deploy(clientset))
+
+	subscription, err := s.Get(clientset)
+	require.NoError(t, err)
+	assert.Equal(t, "events", subscription.Spec.Channel.Name)
+	assert.Equal(t, "Channel", subscription.Spec.Channel.Kind)
+	assert.Equal(t, "bar", subscription.Spec.Subscriber.Ref.Name)
+	assert.Nil(t, subscription.Spec.Reply)
+
+	s.Reply = "results"
+	require.NoError(t, s.Deploy(clientset))
+	list, err := s.List(clientset)
+	require.NoError(t, err)
+	require.Len(t, list.Items, 1)
+	assert.Equal(t, "results", list.Items[0].Spec.Reply.Ref.Name)
+
+	s.Channel = "other"
+	assert.Error(t, s.Deploy(clientset))
+
+	require.NoError(t, s.Delete(clientset))
+	_, err = s.Get(clientset)
+	assert.Error(t, err)
+}
+
+func TestFakeDeployInMemoryChannel(t *testing.T) {
+	clientset := fake.NewConfigSet(
+		&messagingapi.InMemoryChannel{
+			ObjectMeta: metav1.ObjectMeta{Name: "events", Namespace: fake.Namespace},
+		},
+		&messagingapi.Channel{
+			ObjectMeta: metav1.ObjectMeta{Name: "results", Namespace: fake.Namespace},
+		},
+	)
+	s := &Subscription{
+		Name:      "foo",
+		Namespace: fake.Namespace,
+		Channel:   "events",
+		Service:   "bar",
+		Reply:     "results",
+	}
+	require.NoError(t, s.Deploy(clientset))
+
+	subscription, err := s.Get(clientset)
+	require.NoError(t, err)
+	assert.Equal(t, "InMemoryChannel", subscription.Spec.Channel.Kind)
+	assert.Equal(t, "messaging.knative.dev/v1beta1", subscription.Spec.Channel.APIVersion)
+	assert.Equal(t, "Channel", subscription.Spec.Reply.Ref.Kind)
+}
@@ -0,0 +1,26 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package subscription
+
+// Subscription represents knative channel subscription object
+// that delivers channel events to the knative service.
+// If Reply is set, service responses are sent to that channel.
+type Subscription struct {
+	Name      string
+	Namespace string
+	Channel   string
+	Service   string
+	Reply     string
+}
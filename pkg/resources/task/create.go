@@ -128,10 +128,15 @@ func (t *Task) customStep() tekton.Step {
 			Image:   "busybox",
 			Command: []string{"sh"},
 			Args: []string{"-c", fmt.Sprintf(`
-				while [ ! -f %s ]; do 
+				while [ ! -f %[1]s ]; do 
 					sleep 1; 
 				done; 
 				sync;
+				if ! sha256sum -c %[2]s; then
+					echo "Sources archive checksum mismatch, upload is incomplete" >&2;
+					exit 1;
+				fi;
+				tar -xzf %[3]s -C /home;
 				mkdir -p /workspace/workspace;
 				mv /home/*/* /workspace/workspace/;
 				if [[ $? != 0 ]]; then
@@ -139,7 +144,7 @@ func (t *Task) customStep() tekton.Step {
 				fi
 				ls -lah /workspace/workspace;
 				sync;`,
-				uploadDoneTrigger)},
+				uploadDoneTrigger, file.UploadChecksum, file.UploadArchive)},
 		},
 	}
 }
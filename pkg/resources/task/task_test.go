@@ -15,50 +15,45 @@
 package task
 
 import (
-	"os"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
-	"github.com/triggermesh/tm/pkg/client"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/triggermesh/tm/pkg/client/fake"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestList(t *testing.T) {
-	namespace := "test-namespace"
-	if ns, ok := os.LookupEnv("NAMESPACE"); ok {
-		namespace = ns
-	}
-	testClient, err := client.NewClient(client.ConfigPath(""))
-	assert.NoError(t, err)
+	testClient := fake.NewConfigSet(&v1beta1.Task{
+		ObjectMeta: metav1.ObjectMeta{Name: "bar", Namespace: fake.Namespace},
+	})
 
-	task := &Task{Name: "Foo", Namespace: namespace}
+	task := &Task{Name: "Foo", Namespace: fake.Namespace}
 
-	_, err = task.List(&testClient)
+	list, err := task.List(testClient)
 	assert.NoError(t, err)
+	assert.Len(t, list.Items, 1)
 }
 
 func TestGet(t *testing.T) {
-	namespace := "test-namespace"
-	if ns, ok := os.LookupEnv("NAMESPACE"); ok {
-		namespace = ns
-	}
-	testClient, err := client.NewClient(client.ConfigPath(""))
-	assert.NoError(t, err)
+	testClient := fake.NewConfigSet()
 
-	task := &Task{Name: "Foo", Namespace: namespace}
-	result, err := task.Get(&testClient)
-	assert.Error(t, err)
-	assert.Equal(t, "", result.Name)
+	task := &Task{Name: "Foo", Namespace: fake.Namespace}
+	_, err := task.Get(testClient)
+	assert.True(t, k8serrors.IsNotFound(err))
 }
 
 func TestDelete(t *testing.T) {
-	namespace := "test-namespace"
-	if ns, ok := os.LookupEnv("NAMESPACE"); ok {
-		namespace = ns
-	}
-	testClient, err := client.NewClient(client.ConfigPath(""))
-	assert.NoError(t, err)
+	testClient := fake.NewConfigSet(&v1beta1.Task{
+		ObjectMeta: metav1.ObjectMeta{Name: "bar", Namespace: fake.Namespace},
+	})
 
-	task := &Task{Name: "Foo", Namespace: namespace}
-	err = task.Delete(&testClient)
+	task := &Task{Name: "Foo", Namespace: fake.Namespace}
+	err := task.Delete(testClient)
 	assert.Error(t, err)
+
+	task.Name = "bar"
+	err = task.Delete(testClient)
+	assert.NoError(t, err)
 }
@@ -0,0 +1,222 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package taskrun
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/config"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/storage/memory"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/file"
+	"github.com/triggermesh/tm/pkg/registry"
+)
+
+// length of content-addressed image tag
+const digestTagLength = 16
+
+// errCacheDisabled is returned when the registry cannot be queried for the built images
+var errCacheDisabled = errors.New("image build caching is disabled")
+
+// registries that are not queried for the rest of the run
+var (
+	uncachedMutex      sync.Mutex
+	uncachedRegistries = make(map[string]bool)
+)
+
+// CachedImage returns the image that was built earlier from the same sources,
+// runtime task and build arguments. registry.ErrNotFound is returned if
+// the image is not in the registry.
+func (tr *TaskRun) CachedImage(clientset *client.ConfigSet) (string, error) {
+	tr.functionDir(clientset)
+	image, err := tr.imageName(clientset)
+	if err != nil {
+		return "", fmt.Errorf("composing image name: %s", err)
+	}
+	tag, err := tr.sourceDigest(clientset)
+	if err != nil {
+		return "", err
+	}
+	image = fmt.Sprintf("%s:%s", image, tag)
+	digest, err := registryDigest(clientset, tr.Namespace, image)
+	if err != nil {
+		return "", err
+	}
+	tr.imageDigest = digest
+	return image, nil
+}
+
+// registryDigest returns the digest of the built image in the registry.
+// Cluster local registries are not queried unless tm runs in the cluster.
+// If registry is unreachable, caching is disabled for it with a single warning
+// so that the following builds don't wait for the registry timeout.
+func registryDigest(clientset *client.ConfigSet, namespace, image string) (string, error) {
+	host := registry.Host(image)
+	uncachedMutex.Lock()
+	uncached := uncachedRegistries[host]
+	uncachedMutex.Unlock()
+	if uncached {
+		return "", errCacheDisabled
+	}
+	if strings.HasSuffix(host, ".svc.cluster.local") && os.Getenv("KUBERNETES_SERVICE_HOST") == "" {
+		disableCache(clientset, host, "registry is reachable only from the cluster")
+		return "", errCacheDisabled
+	}
+	digest, err := registry.Digest(clientset, namespace, image)
+	if err != nil && err != registry.ErrNotFound {
+		disableCache(clientset, host, err.Error())
+		return "", errCacheDisabled
+	}
+	return digest, err
+}
+
+func disableCache(clientset *client.ConfigSet, host, reason string) {
+	uncachedMutex.Lock()
+	defer uncachedMutex.Unlock()
+	if uncachedRegistries[host] {
+		return
+	}
+	uncachedRegistries[host] = true
+	clientset.Log.Warnf("Image build caching is disabled for %s: %s", host, reason)
+}
+
+// sourceDigest returns image tag derived from function sources,
+// runtime task spec and build arguments, so that the same input
+// always produces the same image tag
+func (tr *TaskRun) sourceDigest(clientset *client.ConfigSet) (string, error) {
+	h := sha256.New()
+
+	switch {
+	case file.IsLocal(tr.Function.Path):
+		if err := hashDir(h, tr.Function.Path); err != nil {
+			return "", fmt.Errorf("hashing sources: %s", err)
+		}
+	case file.IsGit(tr.Function.Path):
+		commit, err := gitCommit(tr.Function.Path, tr.Function.Revision)
+		if err != nil {
+			return "", fmt.Errorf("resolving git revision: %s", err)
+		}
+		fmt.Fprintf(h, "git %s %s\n", tr.Function.Path, commit)
+	default:
+		return "", fmt.Errorf("unknown source type %q", tr.Function.Path)
+	}
+
+	spec, err := tr.taskSpec(clientset)
+	if err != nil {
+		return "", fmt.Errorf("reading task spec: %s", err)
+	}
+	fmt.Fprintf(h, "task %x\n", sha256.Sum256(spec))
+
+	params := append([]string{}, tr.Params...)
+	sort.Strings(params)
+	for _, param := range params {
+		fmt.Fprintf(h, "param %s\n", param)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:digestTagLength], nil
+}
+
+// hashDir writes relative paths, permissions and contents of the files in the directory.
+// Ignored paths are skipped as they are not uploaded and don't affect build result.
+func hashDir(w io.Writer, dir string) error {
+	return file.Walk(dir, func(rel string, info os.FileInfo, err error) error {
+		path := filepath.Join(dir, rel)
+		rel = filepath.ToSlash(rel)
+		switch {
+		case info.Mode()&os.ModeSymlink != 0:
+			target, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "link %s %s\n", rel, target)
+		case info.Mode().IsRegular():
+			fmt.Fprintf(w, "file %s %o %d\n", rel, info.Mode().Perm(), info.Size())
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			if _, err := io.Copy(w, f); err != nil {
+				return err
+			}
+		case info.IsDir():
+			fmt.Fprintf(w, "dir %s\n", rel)
+		}
+		return nil
+	})
+}
+
+var commitHash = regexp.MustCompile("^[0-9a-f]{40}$")
+
+// gitCommit resolves branch or tag name into the commit hash without cloning repository
+func gitCommit(url, revision string) (string, error) {
+	if commitHash.MatchString(revision) {
+		return revision, nil
+	}
+	remote := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{
+		Name: "origin",
+		URLs: []string{url},
+	})
+	refs, err := remote.List(&git.ListOptions{})
+	if err != nil {
+		return "", err
+	}
+	names := []plumbing.ReferenceName{plumbing.HEAD}
+	if revision != "" {
+		names = []plumbing.ReferenceName{
+			plumbing.NewBranchReferenceName(revision),
+			plumbing.NewTagReferenceName(revision),
+		}
+	}
+	for _, name := range names {
+		for _, ref := range refs {
+			if ref.Name() == name && ref.Type() == plumbing.HashReference {
+				return ref.Hash().String(), nil
+			}
+		}
+	}
+	return "", fmt.Errorf("revision %q not found", revision)
+}
+
+// taskSpec returns the contents of the task file or the spec of existing task
+func (tr *TaskRun) taskSpec(clientset *client.ConfigSet) ([]byte, error) {
+	if file.IsLocal(tr.Task.Name) {
+		return ioutil.ReadFile(tr.Task.Name)
+	}
+	task, err := clientset.TektonTasks.TektonV1beta1().Tasks(tr.Namespace).Get(tr.Task.Name, metav1.GetOptions{})
+	if err == nil {
+		return json.Marshal(task.Spec)
+	}
+	clusterTask, err := clientset.TektonTasks.TektonV1beta1().ClusterTasks().Get(tr.Task.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(clusterTask.Spec)
+}
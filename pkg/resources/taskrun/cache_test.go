@@ -0,0 +1,192 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package taskrun
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/triggermesh/tm/pkg/client/fake"
+	"github.com/triggermesh/tm/pkg/registry"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const testManifest = `{
+  "schemaVersion": 2,
+  "mediaType": "application/vnd.docker.distribution.manifest.v2+json",
+  "config": {"mediaType": "application/vnd.docker.container.image.v1+json", "size": 2, "digest": "sha256:44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a"},
+  "layers": []
+}`
+
+func newTask(name, image string) *v1beta1.Task {
+	return &v1beta1.Task{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: fake.Namespace,
+		},
+		Spec: v1beta1.TaskSpec{
+			Steps: []v1beta1.Step{{Container: corev1.Container{Image: image}}},
+		},
+	}
+}
+
+func sourceDir(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "tm-digest")
+	require.NoError(t, err)
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+	}
+	return dir
+}
+
+func TestFakeSourceDigest(t *testing.T) {
+	dir := sourceDir(t, map[string]string{
+		"main.go":     "package main",
+		"lib/util.go": "package lib",
+		".git/HEAD":   "ref: refs/heads/master",
+	})
+	defer os.RemoveAll(dir)
+
+	clientset := fake.NewConfigSet(newTask("kaniko", "kaniko:v1"), newTask("buildpacks", "buildpacks:v1"))
+	tr := &TaskRun{
+		Namespace: fake.Namespace,
+		Function:  Source{Path: dir},
+		Task:      Resource{Name: "kaniko"},
+		Params:    []string{"A=1", "B=2"},
+	}
+	digest, err := tr.sourceDigest(clientset)
+	require.NoError(t, err)
+	assert.Len(t, digest, digestTagLength)
+
+	tr.Params = []string{"B=2", "A=1"}
+	same, err := tr.sourceDigest(clientset)
+	require.NoError(t, err)
+	assert.Equal(t, digest, same, "build arguments order must not change the digest")
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, ".git/HEAD"), []byte("ref: refs/heads/dev"), 0644))
+	same, err = tr.sourceDigest(clientset)
+	require.NoError(t, err)
+	assert.Equal(t, digest, same, "git metadata must not change the digest")
+
+	changes := map[string]func(){
+		"source": func() {
+			require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "lib/util.go"), []byte("package util"), 0644))
+		},
+		"task": func() {
+			tr.Task.Name = "buildpacks"
+		},
+		"params": func() {
+			tr.Params = append(tr.Params, "C=3")
+		},
+	}
+	for _, change := range []string{"source", "task", "params"} {
+		changes[change]()
+		changed, err := tr.sourceDigest(clientset)
+		require.NoError(t, err)
+		assert.NotEqual(t, digest, changed, "%s change must change the digest", change)
+		digest = changed
+	}
+
+	tr.Task.Name = "missing"
+	_, err = tr.sourceDigest(clientset)
+	assert.Error(t, err)
+}
+
+func TestFakeDeploySkipsExistingImage(t *testing.T) {
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Path)
+		switch {
+		case r.URL.Path == "/v2/":
+			w.WriteHeader(http.StatusOK)
+		case strings.HasSuffix(r.URL.Path, "/manifests/missing"):
+			w.WriteHeader(http.StatusNotFound)
+		case strings.Contains(r.URL.Path, "/manifests/"):
+			w.Header().Set("Content-Type", "application/vnd.docker.distribution.manifest.v2+json")
+			w.Write([]byte(testManifest))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	dir := sourceDir(t, map[string]string{"main.go": "package main"})
+	defer os.RemoveAll(dir)
+
+	clientset := fake.NewConfigSet(newTask("kaniko", "kaniko:v1"))
+	clientset.Registry.Host = strings.TrimPrefix(server.URL, "http://")
+	clientset.Registry.SkipTLS = true
+
+	tr := &TaskRun{
+		Name:      "foo",
+		Namespace: fake.Namespace,
+		Function:  Source{Path: dir},
+		Task:      Resource{Name: "kaniko"},
+	}
+	digest, err := tr.sourceDigest(clientset)
+	require.NoError(t, err)
+
+	image, err := tr.Deploy(clientset)
+	require.NoError(t, err)
+	assert.Equal(t, clientset.Registry.Host+"/"+fake.Namespace+"/foo:"+digest, image)
+	assert.Contains(t, requested, "/v2/"+fake.Namespace+"/foo/manifests/"+digest)
+
+	list, err := clientset.TektonTasks.TektonV1beta1().TaskRuns(fake.Namespace).List(metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, list.Items, "taskrun must not be created for existing image")
+
+	assert.Equal(t, "sha256:"+fmt.Sprintf("%x", sha256.Sum256([]byte(testManifest))), tr.ImageDigest())
+
+	_, err = registry.Digest(clientset, fake.Namespace, clientset.Registry.Host+"/"+fake.Namespace+"/foo:missing")
+	assert.Equal(t, registry.ErrNotFound, err)
+}
+
+func TestFakeRegistryDigestDisablesCache(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	closed := listener.Addr().String()
+	listener.Close()
+
+	clientset := fake.NewConfigSet()
+	var output bytes.Buffer
+	clientset.Log.Out = &output
+
+	for _, image := range []string{
+		"knative.registry.svc.cluster.local/foo/bar:v1",
+		"knative.registry.svc.cluster.local/foo/baz:v1",
+		closed + "/foo/bar:v1",
+		closed + "/foo/baz:v1",
+	} {
+		_, err := registryDigest(clientset, fake.Namespace, image)
+		assert.Equal(t, errCacheDisabled, err, image)
+	}
+	assert.Equal(t, 2, strings.Count(output.String(), "Image build caching is disabled"), output.String())
+	assert.Contains(t, output.String(), "reachable only from the cluster")
+}
@@ -29,6 +29,7 @@ import (
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	"github.com/triggermesh/tm/pkg/client"
 	"github.com/triggermesh/tm/pkg/file"
+	"github.com/triggermesh/tm/pkg/registry"
 	"github.com/triggermesh/tm/pkg/resources/clustertask"
 	"github.com/triggermesh/tm/pkg/resources/pipelineresource"
 	"github.com/triggermesh/tm/pkg/resources/task"
@@ -55,6 +56,26 @@ func (tr *TaskRun) Deploy(clientset *client.ConfigSet) (string, error) {
 	if tr.Task.Name == "" {
 		return "", fmt.Errorf("task name cannot be empty")
 	}
+	tr.functionDir(clientset)
+	image, err := tr.imageName(clientset)
+	if err != nil {
+		return "", fmt.Errorf("composing image name: %s", err)
+	}
+	// image tag is derived from the build inputs, if the image
+	// with the same tag exists, there is nothing to build
+	tag, err := tr.sourceDigest(clientset)
+	if err != nil {
+		clientset.Log.Debugf("can't compose content-addressed image tag: %s", err)
+		tag = file.RandString(6)
+	}
+	image = fmt.Sprintf("%s:%s", image, tag)
+	if err == nil && !client.Dry && !tr.ForceBuild {
+		if digest, err := registryDigest(clientset, tr.Namespace, image); err == nil {
+			clientset.Log.Infof("Image %s is up to date, skipping build", image)
+			tr.imageDigest = digest
+			return image, nil
+		}
+	}
 	if !client.Dry {
 		if err := tr.prepareTask(clientset); err != nil {
 			return "", fmt.Errorf("setup task: %s", err)
@@ -66,25 +87,10 @@ func (tr *TaskRun) Deploy(clientset *client.ConfigSet) (string, error) {
 	if err := tr.checkPipelineResource(clientset); err != nil {
 		return "", fmt.Errorf("pipelineresource %q not found", tr.PipelineResource.Name)
 	}
-	image, err := tr.imageName(clientset)
-	if err != nil {
-		return "", fmt.Errorf("composing image name: %s", err)
-	}
-	image = fmt.Sprintf("%s:%s", image, file.RandString(6))
 	clientset.Log.Debugf("taskrun \"%s/%s\" output image will be %q", tr.Namespace, tr.Name, image)
 	taskRunObject := tr.newTaskRun()
 	taskRunObject.Spec.Params = tr.getBuildArguments(image)
 
-	if file.IsLocal(tr.Function.Path) {
-		if file.IsDir(tr.Function.Path) {
-			tr.Function.Path = path.Clean(tr.Function.Path)
-		} else {
-			tr.Params = append(tr.Params, "HANDLER="+path.Base(tr.Function.Path))
-			tr.Function.Path = path.Clean(path.Dir(tr.Function.Path))
-		}
-		clientset.Log.Debugf("function path is %q", tr.Function.Path)
-	}
-
 	if client.Dry {
 		var taskObj []byte
 		if client.Output == "yaml" {
@@ -121,11 +127,22 @@ func (tr *TaskRun) Deploy(clientset *client.ConfigSet) (string, error) {
 		clientset.Log.Debugf("setting pipelineresource owner")
 		tr.setPipelineResourceOwner(clientset, ownerRef)
 	}
-	if file.IsLocal(tr.Function.Path) {
-		pod, err := tr.taskPod(clientset)
-		if err != nil {
+	streamLogs := tr.Wait && !tr.Quiet
+	var pod string
+	if file.IsLocal(tr.Function.Path) || streamLogs {
+		if pod, err = tr.taskPod(clientset); err != nil {
 			return "", fmt.Errorf("getting taskrun pod: %s", err)
 		}
+	}
+	if streamLogs {
+		stop := make(chan struct{})
+		done := tr.followLogs(clientset, pod, stop)
+		defer func() {
+			close(stop)
+			<-done
+		}()
+	}
+	if file.IsLocal(tr.Function.Path) {
 		sourceContainer, err := tr.sourceContainer(clientset, pod)
 		if err != nil {
 			return "", fmt.Errorf("waiting for source container: %s", err)
@@ -140,10 +157,36 @@ func (tr *TaskRun) Deploy(clientset *client.ConfigSet) (string, error) {
 		if err = tr.wait(clientset); err != nil {
 			return image, fmt.Errorf("taskrun %q deployment failed: %s", tr.Name, err)
 		}
+		if taskrun, err := tr.Get(clientset); err == nil {
+			tr.imageDigest = resultDigest(taskrun)
+		}
 	}
 	return image, err
 }
 
+// ImageDigest returns the digest of the image reported by the build
+// or found in registry. Empty string is returned if the digest is unknown.
+func (tr *TaskRun) ImageDigest() string {
+	return tr.imageDigest
+}
+
+// resultDigest looks for the image digest in the taskrun results or
+// in the image output resource results
+func resultDigest(taskrun *v1beta1.TaskRun) string {
+	for _, result := range taskrun.Status.TaskRunResults {
+		switch strings.ToUpper(strings.Replace(result.Name, "-", "_", -1)) {
+		case "IMAGE_DIGEST", "DIGEST":
+			return strings.TrimSpace(result.Value)
+		}
+	}
+	for _, result := range taskrun.Status.ResourcesResult {
+		if result.Key == "digest" {
+			return strings.TrimSpace(result.Value)
+		}
+	}
+	return ""
+}
+
 func (tr *TaskRun) prepareTask(clientset *client.ConfigSet) error {
 	newTask, err := tr.setupTask(clientset)
 	if err != nil {
@@ -277,6 +320,9 @@ func (tr *TaskRun) newTaskRun() *v1beta1.TaskRun {
 			// },
 		},
 	}
+	if tr.ServiceAccount != "" {
+		taskrun.Spec.ServiceAccountName = tr.ServiceAccount
+	}
 	if tr.PipelineResource.Name != "" {
 		taskrun.Spec.Resources.Inputs = []v1beta1.TaskResourceBinding{
 			{
@@ -293,6 +339,21 @@ func (tr *TaskRun) newTaskRun() *v1beta1.TaskRun {
 	return taskrun
 }
 
+// functionDir replaces local function file path with its directory
+// and passes the file name to the build as the handler
+func (tr *TaskRun) functionDir(clientset *client.ConfigSet) {
+	if !file.IsLocal(tr.Function.Path) {
+		return
+	}
+	if file.IsDir(tr.Function.Path) {
+		tr.Function.Path = path.Clean(tr.Function.Path)
+	} else {
+		tr.Params = append(tr.Params, "HANDLER="+path.Base(tr.Function.Path))
+		tr.Function.Path = path.Clean(path.Dir(tr.Function.Path))
+	}
+	clientset.Log.Debugf("function path is %q", tr.Function.Path)
+}
+
 func (tr *TaskRun) imageName(clientset *client.ConfigSet) (string, error) {
 	if len(clientset.Registry.Secret) == 0 {
 		return fmt.Sprintf("%s/%s/%s", clientset.Registry.Host, tr.Namespace, tr.Name), nil
@@ -307,19 +368,29 @@ func (tr *TaskRun) imageName(clientset *client.ConfigSet) (string, error) {
 	if err := dec.Decode(&config); err != nil {
 		return "", err
 	}
-	if len(config.Auths) > 1 {
-		return "", errors.New("credentials with multiple registries not supported")
-	}
 	if url, ok := gitlabEnv(); ok {
 		return fmt.Sprintf("%s/%s", url, tr.Name), nil
 	}
-	for host, creds := range config.Auths {
-		if config.Project != "" {
-			return fmt.Sprintf("%s/%s/%s", host, config.Project, tr.Name), nil
+	host := config.PushRegistry
+	if host == "" {
+		if len(config.Auths) > 1 {
+			return "", errors.New("credentials with multiple registries must have push registry set")
+		}
+		for h := range config.Auths {
+			host = h
+		}
+		if host == "" {
+			return "", errors.New("empty registry credentials")
 		}
-		return fmt.Sprintf("%s/%s/%s", host, creds.Username, tr.Name), nil
 	}
-	return "", errors.New("empty registry credentials")
+	creds, ok := config.Auths[host]
+	if !ok {
+		return "", fmt.Errorf("push registry %q has no credentials", host)
+	}
+	if config.Project != "" {
+		return fmt.Sprintf("%s/%s/%s", registry.Host(host), config.Project, tr.Name), nil
+	}
+	return fmt.Sprintf("%s/%s/%s", registry.Host(host), creds.Username, tr.Name), nil
 }
 
 // hack to use correct username in image URL instead of "gitlab-ci-token" in Gitlab CI
@@ -464,14 +535,14 @@ func (tr *TaskRun) injectSources(clientset *client.ConfigSet, pod, container str
 		Source:      tr.Function.Path,
 		Destination: path.Join("/home", path.Base(tr.Function.Path)),
 	}
-	if err := c.Upload(clientset); err != nil {
-		return err
-	}
+	uploadErr := c.Upload(clientset)
+	// completion flag is created even if upload failed: without the checksum file
+	// sources receiver fails right away instead of waiting for the build timeout
 	clientset.Log.Debugf("creating upload completion flag")
-	if _, _, err := c.RemoteExec(clientset, "touch "+uploadDoneTrigger, nil); err != nil {
+	if _, _, err := c.RemoteExec(clientset, "touch "+uploadDoneTrigger, nil); err != nil && uploadErr == nil {
 		return err
 	}
-	return nil
+	return uploadErr
 }
 
 func (tr *TaskRun) getBuildArguments(image string) []v1beta1.Param {
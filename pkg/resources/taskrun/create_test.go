@@ -29,6 +29,7 @@ func TestTaskRunDryDeployment(t *testing.T) {
 	// }
 
 	client.Dry = true
+	defer func() { client.Dry = false }()
 	clientset, err := client.NewClient("../../../testfiles/cfgfile-test.json")
 	assert.NoError(t, err)
 
@@ -0,0 +1,39 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package taskrun
+
+import (
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/resources/event"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Events returns events of the taskrun and its pod
+func (tr *TaskRun) Events(taskrun *v1beta1.TaskRun, clientset *client.ConfigSet) ([]corev1.Event, error) {
+	objects := []metav1.Object{taskrun}
+	if name := taskrun.Status.PodName; name != "" {
+		pod, err := clientset.Core.CoreV1().Pods(tr.Namespace).Get(name, metav1.GetOptions{})
+		if err == nil {
+			objects = append(objects, pod)
+		} else if !k8serrors.IsNotFound(err) {
+			return nil, err
+		}
+	}
+	e := event.Event{Namespace: tr.Namespace}
+	return e.List(clientset, objects...)
+}
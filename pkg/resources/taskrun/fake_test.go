@@ -0,0 +1,167 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package taskrun
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/triggermesh/tm/pkg/client/fake"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"knative.dev/pkg/apis"
+)
+
+func newTaskRun(name, pod string, conditions ...apis.Condition) *v1beta1.TaskRun {
+	taskrun := &v1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: fake.Namespace,
+		},
+	}
+	taskrun.Status.PodName = pod
+	taskrun.Status.Conditions = conditions
+	return taskrun
+}
+
+func TestFakeGetListDeleteSetOwner(t *testing.T) {
+	clientset := fake.NewConfigSet(newTaskRun("foo", ""), newTaskRun("bar", ""))
+	tr := &TaskRun{Name: "foo", Namespace: fake.Namespace}
+
+	list, err := tr.List(clientset)
+	require.NoError(t, err)
+	assert.Len(t, list.Items, 2)
+
+	owner := metav1.OwnerReference{Kind: "Configuration", Name: "foo"}
+	require.NoError(t, tr.SetOwner(clientset, owner))
+	taskrun, err := tr.Get(clientset)
+	require.NoError(t, err)
+	assert.Equal(t, []metav1.OwnerReference{owner}, taskrun.OwnerReferences)
+
+	require.NoError(t, tr.Delete(clientset))
+	_, err = tr.Get(clientset)
+	assert.Error(t, err)
+}
+
+func TestFakeTaskPod(t *testing.T) {
+	clientset := fake.NewConfigSet()
+	fake.ScriptWatch(clientset.TektonTasks, "taskruns",
+		watch.Event{Type: watch.Added, Object: newTaskRun("foo", "")},
+		watch.Event{Type: watch.Modified, Object: newTaskRun("foo", "foo-pod")},
+	)
+
+	tr := &TaskRun{Name: "foo", Namespace: fake.Namespace}
+	pod, err := tr.taskPod(clientset)
+	require.NoError(t, err)
+	assert.Equal(t, "foo-pod", pod)
+}
+
+func TestFakeWait(t *testing.T) {
+	testCases := []struct {
+		name      string
+		condition apis.Condition
+		wantErr   string
+	}{
+		{
+			name: "taskrun succeeded",
+			condition: apis.Condition{
+				Type:   apis.ConditionSucceeded,
+				Status: corev1.ConditionTrue,
+			},
+		}, {
+			name: "taskrun failed",
+			condition: apis.Condition{
+				Type:     apis.ConditionSucceeded,
+				Status:   corev1.ConditionFalse,
+				Severity: apis.ConditionSeverityError,
+				Message:  "build failed",
+			},
+			wantErr: "build failed",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			clientset := fake.NewConfigSet()
+			fake.ScriptWatch(clientset.TektonTasks, "taskruns",
+				watch.Event{Type: watch.Added, Object: newTaskRun("foo", "foo-pod")},
+				watch.Event{Type: watch.Modified, Object: newTaskRun("foo", "foo-pod", tc.condition)},
+			)
+
+			tr := &TaskRun{Name: "foo", Namespace: fake.Namespace}
+			err := tr.wait(clientset)
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestFakeImageName(t *testing.T) {
+	testCases := []struct {
+		name    string
+		config  string
+		image   string
+		wantErr bool
+	}{
+		{
+			name:   "single registry",
+			config: `{"auths":{"registry.example.com":{"username":"foo","password":"bar"}}}`,
+			image:  "registry.example.com/foo/bar",
+		}, {
+			name:   "project",
+			config: `{"project":"baz","auths":{"gcr.io":{"username":"_json_key","password":"bar"}}}`,
+			image:  "gcr.io/baz/bar",
+		}, {
+			name:   "push registry",
+			config: `{"pushRegistry":"gcr.io","auths":{"registry.example.com":{"username":"foo"},"gcr.io":{"username":"baz"}}}`,
+			image:  "gcr.io/baz/bar",
+		}, {
+			name:   "docker hub",
+			config: `{"auths":{"https://index.docker.io/v1/":{"username":"foo","password":"bar"}}}`,
+			image:  "index.docker.io/foo/bar",
+		}, {
+			name:    "multiple registries without push registry",
+			config:  `{"auths":{"registry.example.com":{"username":"foo"},"gcr.io":{"username":"baz"}}}`,
+			wantErr: true,
+		}, {
+			name:    "push registry without credentials",
+			config:  `{"pushRegistry":"quay.io","auths":{"gcr.io":{"username":"baz"}}}`,
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			clientset := fake.NewConfigSet(&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "registry", Namespace: fake.Namespace},
+				Data:       map[string][]byte{"config.json": []byte(tc.config)},
+			})
+			clientset.Registry.Secret = "registry"
+			tr := &TaskRun{Name: "bar", Namespace: fake.Namespace}
+			image, err := tr.imageName(clientset)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.image, image)
+		})
+	}
+}
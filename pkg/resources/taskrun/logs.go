@@ -0,0 +1,160 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package taskrun
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/triggermesh/tm/pkg/client"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	stepPrefix = "step-"
+	// time to wait for the rest of the logs after the build is finished
+	logsGracePeriod = 5 * time.Second
+)
+
+// Logs writes logs of all TaskRun steps to the output.
+// If follow is set, logs are streamed until the last step is finished.
+func (tr *TaskRun) Logs(clientset *client.ConfigSet, options *corev1.PodLogOptions, output io.Writer) error {
+	taskrun, err := tr.Get(clientset)
+	if err != nil {
+		return err
+	}
+	if taskrun.Status.PodName == "" {
+		return fmt.Errorf("taskrun %q has no pod yet", tr.Name)
+	}
+	return tr.streamLogs(clientset, taskrun.Status.PodName, options, output, nil)
+}
+
+// followLogs starts build logs streaming in background
+// and returns the channel that is closed when streaming is finished
+func (tr *TaskRun) followLogs(clientset *client.ConfigSet, podName string, stop <-chan struct{}) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		options := &corev1.PodLogOptions{Follow: true}
+		if err := tr.streamLogs(clientset, podName, options, clientset.Log.Out, stop); err != nil {
+			clientset.Log.Warnf("Build logs streaming interrupted: %s", err)
+		}
+	}()
+	return done
+}
+
+// streamLogs reads TaskRun pod step containers logs one by one and writes them
+// to the output with the step name prefix. In follow mode streaming stops when
+// all steps are finished or when stop channel is closed and remaining steps
+// have not been started. Otherwise, steps that are not started are skipped.
+func (tr *TaskRun) streamLogs(clientset *client.ConfigSet, podName string, options *corev1.PodLogOptions, output io.Writer, stop <-chan struct{}) error {
+	pod, err := clientset.Core.CoreV1().Pods(tr.Namespace).Get(podName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	for _, container := range pod.Spec.Containers {
+		if !strings.HasPrefix(container.Name, stepPrefix) {
+			continue
+		}
+		if options.Follow {
+			started, err := tr.waitContainer(clientset, podName, container.Name, stop)
+			if err != nil {
+				return err
+			}
+			if !started {
+				return nil
+			}
+		} else if !containerStarted(pod, container.Name) {
+			continue
+		}
+		opts := options.DeepCopy()
+		opts.Container = container.Name
+		if err := tr.copyLogs(clientset, podName, opts, output, stop); err != nil {
+			return fmt.Errorf("step %q logs: %s", container.Name, err)
+		}
+	}
+	return nil
+}
+
+// waitContainer blocks until pod container is started.
+// It returns false if stop channel is closed or pod is finished before that
+// and error if pod does not exist anymore.
+func (tr *TaskRun) waitContainer(clientset *client.ConfigSet, podName, container string, stop <-chan struct{}) (bool, error) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		pod, err := clientset.Core.CoreV1().Pods(tr.Namespace).Get(podName, metav1.GetOptions{})
+		switch {
+		case k8serrors.IsNotFound(err):
+			return false, err
+		case err != nil:
+			clientset.Log.Debugf("cannot get %q pod: %s", podName, err)
+		case containerStarted(pod, container):
+			return true, nil
+		case pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed:
+			return false, nil
+		}
+		select {
+		case <-stop:
+			return false, nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func containerStarted(pod *corev1.Pod, container string) bool {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == container {
+			return status.State.Running != nil || status.State.Terminated != nil
+		}
+	}
+	return false
+}
+
+// copyLogs reads container logs and writes them line by line with the step name prefix.
+// If stop channel is closed, stream is closed after the grace period.
+func (tr *TaskRun) copyLogs(clientset *client.ConfigSet, podName string, options *corev1.PodLogOptions, output io.Writer, stop <-chan struct{}) error {
+	stream, err := clientset.Core.CoreV1().Pods(tr.Namespace).GetLogs(podName, options).Stream()
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	finished := make(chan struct{})
+	defer close(finished)
+	go func() {
+		select {
+		case <-stop:
+			select {
+			case <-time.After(logsGracePeriod):
+				stream.Close()
+			case <-finished:
+			}
+		case <-finished:
+		}
+	}()
+
+	step := strings.TrimPrefix(options.Container, stepPrefix)
+	scanner := bufio.NewScanner(stream)
+	for scanner.Scan() {
+		fmt.Fprintf(output, "[%s] %s\n", step, scanner.Text())
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,151 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package taskrun
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/triggermesh/tm/pkg/client/fake"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// newStepsPod returns taskrun pod with build and push steps
+// where only the given steps are started
+func newStepsPod(name string, phase corev1.PodPhase, started ...string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: fake.Namespace},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{Name: "place-tools"},
+				{Name: "step-build"},
+				{Name: "step-push"},
+			},
+		},
+		Status: corev1.PodStatus{Phase: phase},
+	}
+	for _, container := range pod.Spec.Containers {
+		status := corev1.ContainerStatus{Name: container.Name}
+		for _, s := range started {
+			if s == container.Name {
+				status.State.Terminated = &corev1.ContainerStateTerminated{}
+			}
+		}
+		pod.Status.ContainerStatuses = append(pod.Status.ContainerStatuses, status)
+	}
+	return pod
+}
+
+var stepsLogs = map[string]string{
+	"foo-pod/place-tools": "tools\n",
+	"foo-pod/step-build":  "building\ndone\n",
+	"foo-pod/step-push":   "pushed\n",
+}
+
+func TestFakeLogs(t *testing.T) {
+	testCases := []struct {
+		name    string
+		pod     *corev1.Pod
+		follow  bool
+		logs    string
+		wantErr bool
+	}{
+		{
+			name: "finished steps",
+			pod:  newStepsPod("foo-pod", corev1.PodSucceeded, "step-build", "step-push"),
+			logs: "[build] building\n[build] done\n[push] pushed\n",
+		}, {
+			name: "not started step is skipped",
+			pod:  newStepsPod("foo-pod", corev1.PodRunning, "step-build"),
+			logs: "[build] building\n[build] done\n",
+		}, {
+			name:   "follow finished steps",
+			pod:    newStepsPod("foo-pod", corev1.PodSucceeded, "step-build", "step-push"),
+			follow: true,
+			logs:   "[build] building\n[build] done\n[push] pushed\n",
+		}, {
+			name:   "follow failed pod",
+			pod:    newStepsPod("foo-pod", corev1.PodFailed, "step-build"),
+			follow: true,
+			logs:   "[build] building\n[build] done\n",
+		}, {
+			name:    "missing pod",
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			clientset := fake.NewConfigSet(newTaskRun("foo", "foo-pod"))
+			if tc.pod != nil {
+				clientset = fake.NewConfigSet(newTaskRun("foo", "foo-pod"), tc.pod)
+			}
+			fake.ScriptLogs(clientset, stepsLogs)
+
+			var output bytes.Buffer
+			tr := &TaskRun{Name: "foo", Namespace: fake.Namespace}
+			err := tr.Logs(clientset, &corev1.PodLogOptions{Follow: tc.follow}, &output)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.logs, output.String())
+		})
+	}
+}
+
+func TestFakeLogsWithoutPod(t *testing.T) {
+	clientset := fake.NewConfigSet(newTaskRun("foo", ""))
+	tr := &TaskRun{Name: "foo", Namespace: fake.Namespace}
+	assert.EqualError(t, tr.Logs(clientset, &corev1.PodLogOptions{}, &bytes.Buffer{}), `taskrun "foo" has no pod yet`)
+}
+
+func TestFakeWaitContainer(t *testing.T) {
+	tr := &TaskRun{Name: "foo", Namespace: fake.Namespace}
+
+	clientset := fake.NewConfigSet()
+	_, err := tr.waitContainer(clientset, "foo-pod", "step-build", nil)
+	assert.True(t, k8serrors.IsNotFound(err))
+
+	clientset = fake.NewConfigSet(newStepsPod("foo-pod", corev1.PodRunning, "step-build"))
+	started, err := tr.waitContainer(clientset, "foo-pod", "step-build", nil)
+	require.NoError(t, err)
+	assert.True(t, started)
+
+	stop := make(chan struct{})
+	close(stop)
+	started, err = tr.waitContainer(clientset, "foo-pod", "step-push", stop)
+	require.NoError(t, err)
+	assert.False(t, started)
+}
+
+func TestFakeFollowLogs(t *testing.T) {
+	clientset := fake.NewConfigSet(newStepsPod("foo-pod", corev1.PodRunning, "step-build"))
+	fake.ScriptLogs(clientset, stepsLogs)
+	var output bytes.Buffer
+	clientset.Log.Out = &output
+
+	// push step is never started, streaming ends when build is stopped
+	stop := make(chan struct{})
+	tr := &TaskRun{Name: "foo", Namespace: fake.Namespace}
+	done := tr.followLogs(clientset, "foo-pod", stop)
+	close(stop)
+	<-done
+	assert.Equal(t, "[build] building\n[build] done\n", output.String())
+}
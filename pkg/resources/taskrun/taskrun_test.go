@@ -15,50 +15,45 @@
 package taskrun
 
 import (
-	"os"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
-	"github.com/triggermesh/tm/pkg/client"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/triggermesh/tm/pkg/client/fake"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestList(t *testing.T) {
-	namespace := "test-namespace"
-	if ns, ok := os.LookupEnv("NAMESPACE"); ok {
-		namespace = ns
-	}
-	testClient, err := client.NewClient(client.ConfigPath(""))
-	assert.NoError(t, err)
+	testClient := fake.NewConfigSet(&v1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "bar", Namespace: fake.Namespace},
+	})
 
-	taskRun := &TaskRun{Name: "Foo", Namespace: namespace}
+	taskRun := &TaskRun{Name: "Foo", Namespace: fake.Namespace}
 
-	_, err = taskRun.List(&testClient)
+	list, err := taskRun.List(testClient)
 	assert.NoError(t, err)
+	assert.Len(t, list.Items, 1)
 }
 
 func TestGet(t *testing.T) {
-	namespace := "test-namespace"
-	if ns, ok := os.LookupEnv("NAMESPACE"); ok {
-		namespace = ns
-	}
-	testClient, err := client.NewClient(client.ConfigPath(""))
-	assert.NoError(t, err)
+	testClient := fake.NewConfigSet()
 
-	taskRun := &TaskRun{Name: "Foo", Namespace: namespace}
-	result, err := taskRun.Get(&testClient)
-	assert.Error(t, err)
-	assert.Equal(t, "", result.Name)
+	taskRun := &TaskRun{Name: "Foo", Namespace: fake.Namespace}
+	_, err := taskRun.Get(testClient)
+	assert.True(t, k8serrors.IsNotFound(err))
 }
 
 func TestDelete(t *testing.T) {
-	namespace := "test-namespace"
-	if ns, ok := os.LookupEnv("NAMESPACE"); ok {
-		namespace = ns
-	}
-	testClient, err := client.NewClient(client.ConfigPath(""))
-	assert.NoError(t, err)
+	testClient := fake.NewConfigSet(&v1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "bar", Namespace: fake.Namespace},
+	})
 
-	taskRun := &TaskRun{Name: "Foo", Namespace: namespace}
-	err = taskRun.Delete(&testClient)
+	taskRun := &TaskRun{Name: "Foo", Namespace: fake.Namespace}
+	err := taskRun.Delete(testClient)
 	assert.Error(t, err)
+
+	taskRun.Name = "bar"
+	err = taskRun.Delete(testClient)
+	assert.NoError(t, err)
 }
@@ -16,14 +16,22 @@ package taskrun
 
 // TaskRun represents tekton TaskRun object
 type TaskRun struct {
-	Function         Source
-	Name             string
-	Namespace        string
+	Function  Source
+	Name      string
+	Namespace string
+	// ForceBuild disables the check for existing image built from the same sources
+	ForceBuild       bool
 	Params           []string
 	PipelineResource Resource
-	Task             Resource
-	Timeout          string
-	Wait             bool
+	// Quiet disables build logs streaming while waiting for the result
+	Quiet bool
+	// ServiceAccount is the name of service account that build pod runs with
+	ServiceAccount string
+	Task           Resource
+	Timeout        string
+	Wait           bool
+
+	imageDigest string
 }
 
 // Resource is a generic structure to describe k8s resource
@@ -41,8 +49,9 @@ type Source struct {
 }
 
 type registryAuths struct {
-	Project string
-	Auths   registry
+	Project      string
+	PushRegistry string
+	Auths        registryHosts
 }
 
 type credentials struct {
@@ -50,4 +59,4 @@ type credentials struct {
 	Password string
 }
 
-type registry map[string]credentials
+type registryHosts map[string]credentials
@@ -0,0 +1,116 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trigger
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/triggermesh/tm/pkg/client"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	eventingapi "knative.dev/eventing/pkg/apis/eventing/v1beta1"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+const defaultBroker = "default"
+
+// ParseFilter converts "attribute=value" pairs into trigger filter attributes
+func ParseFilter(filter []string) (map[string]string, error) {
+	attributes := make(map[string]string)
+	for _, f := range filter {
+		kv := strings.SplitN(f, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf("malformed filter %q, must be in \"attribute=value\" format", f)
+		}
+		attributes[kv[0]] = kv[1]
+	}
+	return attributes, nil
+}
+
+// Deploy knative eventing trigger
+func (t *Trigger) Deploy(clientset *client.ConfigSet) error {
+	triggerObject, err := t.NewObject()
+	if err != nil {
+		return err
+	}
+	if client.Dry {
+		res, err := yaml.Marshal(triggerObject)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s\n", res)
+		return nil
+	}
+	return t.createOrUpdate(triggerObject, clientset)
+}
+
+// NewObject returns trigger object with knative service as a subscriber
+func (t *Trigger) NewObject() (*eventingapi.Trigger, error) {
+	if t.Service == "" {
+		return nil, errors.New("trigger subscriber service is not set")
+	}
+	broker := t.Broker
+	if broker == "" {
+		broker = defaultBroker
+	}
+	trigger := &eventingapi.Trigger{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Trigger",
+			APIVersion: "eventing.knative.dev/v1beta1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      t.Name,
+			Namespace: t.Namespace,
+		},
+		Spec: eventingapi.TriggerSpec{
+			Broker: broker,
+			Subscriber: duckv1.Destination{
+				Ref: &duckv1.KReference{
+					APIVersion: "serving.knative.dev/v1",
+					Kind:       "Service",
+					Name:       t.Service,
+					Namespace:  t.Namespace,
+				},
+			},
+		},
+	}
+	if len(t.Filter) != 0 {
+		trigger.Spec.Filter = &eventingapi.TriggerFilter{
+			Attributes: eventingapi.TriggerFilterAttributes(t.Filter),
+		}
+	}
+	return trigger, nil
+}
+
+func (t *Trigger) createOrUpdate(triggerObject *eventingapi.Trigger, clientset *client.ConfigSet) error {
+	_, err := clientset.Eventing.EventingV1beta1().Triggers(t.Namespace).Create(triggerObject)
+	if k8serrors.IsAlreadyExists(err) {
+		trigger, err := clientset.Eventing.EventingV1beta1().Triggers(t.Namespace).Get(triggerObject.ObjectMeta.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if trigger.Spec.Broker != triggerObject.Spec.Broker {
+			return fmt.Errorf("trigger %q already exists in broker %q, broker cannot be changed", t.Name, trigger.Spec.Broker)
+		}
+		triggerObject.ObjectMeta.ResourceVersion = trigger.GetResourceVersion()
+		triggerObject.ObjectMeta.Annotations = trigger.GetAnnotations()
+		_, err = clientset.Eventing.EventingV1beta1().Triggers(t.Namespace).Update(triggerObject)
+		return err
+	}
+	return err
+}
@@ -0,0 +1,83 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trigger
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/printer"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+	eventingapi "knative.dev/eventing/pkg/apis/eventing/v1beta1"
+)
+
+// GetTable converts k8s list instance into printable object
+func (t *Trigger) GetTable(list *eventingapi.TriggerList) printer.Table {
+	table := printer.Table{
+		Headers: []string{
+			"Namespace",
+			"Name",
+			"Broker",
+			"Subscriber",
+			"Age",
+			"Ready",
+			"Reason",
+		},
+		Rows: make([][]string, 0, len(list.Items)),
+	}
+
+	for _, item := range list.Items {
+		table.Rows = append(table.Rows, t.row(&item))
+	}
+	return table
+}
+
+func (t *Trigger) row(item *eventingapi.Trigger) []string {
+	name := item.Name
+	namespace := item.Namespace
+	broker := item.Spec.Broker
+	subscriber := ""
+	if ref := item.Spec.Subscriber.Ref; ref != nil {
+		subscriber = ref.Kind + "/" + ref.Name
+	} else if uri := item.Spec.Subscriber.URI; uri != nil {
+		subscriber = uri.String()
+	}
+	age := duration.HumanDuration(time.Since(item.GetCreationTimestamp().Time))
+	ready := fmt.Sprintf("%v", item.Status.IsReady())
+	readyCondition := item.Status.GetCondition(eventingapi.TriggerConditionReady)
+	reason := ""
+	if readyCondition != nil {
+		reason = readyCondition.Reason
+	}
+
+	row := []string{
+		namespace,
+		name,
+		broker,
+		subscriber,
+		age,
+		ready,
+		reason,
+	}
+
+	return row
+}
+
+// List returns list of knative trigger objects
+func (t *Trigger) List(clientset *client.ConfigSet) (*eventingapi.TriggerList, error) {
+	return clientset.Eventing.EventingV1beta1().Triggers(t.Namespace).List(metav1.ListOptions{})
+}
@@ -0,0 +1,70 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trigger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/triggermesh/tm/pkg/client/fake"
+)
+
+func TestParseFilter(t *testing.T) {
+	filter, err := ParseFilter([]string{"type=dev.knative.foo", "source=https://foo.bar?a=b"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"type":   "dev.knative.foo",
+		"source": "https://foo.bar?a=b",
+	}, filter)
+
+	_, err = ParseFilter([]string{"type"})
+	assert.Error(t, err)
+	_, err = ParseFilter([]string{"=foo"})
+	assert.Error(t, err)
+}
+
+func TestFakeDeployGetListDelete(t *testing.T) {
+	clientset := fake.NewConfigSet()
+	tg := &Trigger{
+		Name:      "foo",
+		Namespace: fake.Namespace,
+		Filter:    map[string]string{"type": "dev.knative.foo"},
+	}
+	assert.Error(t, tg.Deploy(clientset))
+
+	tg.Service = "bar"
+	require.NoError(t, tg.Deploy(clientset))
+
+	trigger, err := tg.Get(clientset)
+	require.NoError(t, err)
+	assert.Equal(t, "default", trigger.Spec.Broker)
+	assert.Equal(t, "bar", trigger.Spec.Subscriber.Ref.Name)
+	assert.Equal(t, "dev.knative.foo", trigger.Spec.Filter.Attributes["type"])
+
+	tg.Filter = nil
+	require.NoError(t, tg.Deploy(clientset))
+	list, err := tg.List(clientset)
+	require.NoError(t, err)
+	require.Len(t, list.Items, 1)
+	assert.Nil(t, list.Items[0].Spec.Filter)
+
+	tg.Broker = "other"
+	assert.Error(t, tg.Deploy(clientset))
+
+	require.NoError(t, tg.Delete(clientset))
+	_, err = tg.Get(clientset)
+	assert.Error(t, err)
+}
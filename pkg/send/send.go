@@ -0,0 +1,219 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package send
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/url"
+	"strings"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+	"github.com/google/uuid"
+	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/resources/broker"
+	"github.com/triggermesh/tm/pkg/resources/service"
+)
+
+const defaultContentType = "application/json"
+
+// Event contains CloudEvent attributes and delivery parameters.
+// Target is either "service/<name>", "broker/<name>" or URL.
+type Event struct {
+	Target      string
+	Namespace   string
+	Type        string
+	Source      string
+	Data        string
+	ContentType string
+	Structured  bool
+	// Count of the events to send and the Rate per second they are sent with
+	Count int
+	Rate  float64
+}
+
+// deliveryStats contains the results of sending multiple events
+type deliveryStats struct {
+	Sent     int
+	Failed   int
+	Duration time.Duration
+	Latency  time.Duration
+}
+
+// Send delivers events to the target and writes the reply event
+// or delivery statistics to the output
+func (e *Event) Send(clientset *client.ConfigSet, output io.Writer) error {
+	if e.Rate < 0 || math.IsNaN(e.Rate) {
+		return fmt.Errorf("rate must be a positive number, got %v", e.Rate)
+	}
+	target, err := e.targetURL(clientset)
+	if err != nil {
+		return err
+	}
+	event, err := e.cloudEvent()
+	if err != nil {
+		return err
+	}
+	c, err := cloudevents.NewDefaultClient()
+	if err != nil {
+		return fmt.Errorf("creating CloudEvents client: %s", err)
+	}
+	ctx := cloudevents.ContextWithTarget(context.Background(), target)
+	if e.Structured {
+		ctx = cloudevents.WithEncodingStructured(ctx)
+	}
+
+	if e.Count <= 1 {
+		reply, err := request(ctx, c, event)
+		if err != nil {
+			return err
+		}
+		if reply == nil {
+			fmt.Fprintln(output, "Event accepted, no reply")
+			return nil
+		}
+		fmt.Fprint(output, reply.String())
+		return nil
+	}
+
+	stats := e.load(ctx, c, event)
+	fmt.Fprintf(output, "Sent: %d, failed: %d, duration: %s, average latency: %s\n",
+		stats.Sent, stats.Failed, stats.Duration.Round(time.Millisecond), stats.Latency.Round(time.Microsecond))
+	if stats.Failed != 0 {
+		return fmt.Errorf("%d of %d events were not delivered", stats.Failed, stats.Sent)
+	}
+	return nil
+}
+
+// load sends Count events with the given Rate and collects delivery statistics
+func (e *Event) load(ctx context.Context, c cloudevents.Client, event cloudevents.Event) deliveryStats {
+	var stats deliveryStats
+	var throttle <-chan time.Time
+	if e.Rate > 0 {
+		ticker := time.NewTicker(e.interval())
+		defer ticker.Stop()
+		throttle = ticker.C
+	}
+	start := time.Now()
+	for i := 0; i < e.Count; i++ {
+		if throttle != nil && i != 0 {
+			<-throttle
+		}
+		event.SetID(uuid.New().String())
+		sent := time.Now()
+		if _, err := request(ctx, c, event); err != nil {
+			stats.Failed++
+		}
+		stats.Latency += time.Since(sent)
+		stats.Sent++
+	}
+	stats.Duration = time.Since(start)
+	stats.Latency /= time.Duration(stats.Sent)
+	return stats
+}
+
+// interval returns the delay between the events sent with the Rate.
+// Delay is clamped to the values that ticker can handle.
+func (e *Event) interval() time.Duration {
+	interval := float64(time.Second) / e.Rate
+	switch {
+	case interval < 1:
+		return time.Nanosecond
+	case interval >= math.MaxInt64:
+		return math.MaxInt64
+	}
+	return time.Duration(interval)
+}
+
+func request(ctx context.Context, c cloudevents.Client, event cloudevents.Event) (*cloudevents.Event, error) {
+	reply, result := c.Request(ctx, event)
+	if !cloudevents.IsACK(result) {
+		var httpResult *cehttp.Result
+		if errors.As(result, &httpResult) {
+			return nil, fmt.Errorf("event was not accepted, status code %d", httpResult.StatusCode)
+		}
+		return nil, fmt.Errorf("sending event: %s", result)
+	}
+	return reply, nil
+}
+
+// cloudEvent composes CloudEvent from the parameters.
+// Data prefixed with "@" is read from the file.
+func (e *Event) cloudEvent() (cloudevents.Event, error) {
+	event := cloudevents.NewEvent()
+	event.SetType(e.Type)
+	event.SetSource(e.Source)
+	if e.Data != "" {
+		data := []byte(e.Data)
+		if strings.HasPrefix(e.Data, "@") {
+			var err error
+			if data, err = ioutil.ReadFile(strings.TrimPrefix(e.Data, "@")); err != nil {
+				return event, fmt.Errorf("reading event data: %s", err)
+			}
+		}
+		contentType := e.ContentType
+		if contentType == "" {
+			contentType = defaultContentType
+		}
+		event.SetDataContentType(contentType)
+		event.DataEncoded = data
+	}
+	event.SetID(uuid.New().String())
+	return event, event.Validate()
+}
+
+// targetURL resolves target address from the service or broker status
+func (e *Event) targetURL(clientset *client.ConfigSet) (string, error) {
+	kind := ""
+	name := e.Target
+	if parts := strings.SplitN(e.Target, "/", 2); len(parts) == 2 && !strings.Contains(parts[0], ":") {
+		kind, name = parts[0], parts[1]
+	}
+	switch kind {
+	case "service", "services", "svc":
+		s := service.Service{Name: name, Namespace: e.Namespace}
+		ksvc, err := s.Get(clientset)
+		if err != nil {
+			return "", err
+		}
+		if ksvc.Status.URL == nil {
+			return "", fmt.Errorf("service %q has no URL, is it ready?", name)
+		}
+		return ksvc.Status.URL.String(), nil
+	case "broker", "brokers":
+		b := broker.Broker{Name: name, Namespace: e.Namespace}
+		kbroker, err := b.Get(clientset)
+		if err != nil {
+			return "", err
+		}
+		if kbroker.Status.Address.URL == nil {
+			return "", fmt.Errorf("broker %q has no address, is it ready?", name)
+		}
+		return kbroker.Status.Address.URL.String(), nil
+	case "":
+		u, err := url.Parse(e.Target)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return "", fmt.Errorf("target %q must be either service/<name>, broker/<name> or URL", e.Target)
+		}
+		return e.Target, nil
+	}
+	return "", fmt.Errorf("unsupported target kind %q", kind)
+}
@@ -0,0 +1,169 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package send
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/triggermesh/tm/pkg/client/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	eventingv1beta1 "knative.dev/eventing/pkg/apis/eventing/v1beta1"
+	"knative.dev/pkg/apis"
+	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
+)
+
+func TestSend(t *testing.T) {
+	var contentType string
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		body, _ = ioutil.ReadAll(r.Body)
+		// structured mode event attributes are in the body
+		if r.Header.Get("Ce-Type") == "dev.tm.noreply" || bytes.Contains(body, []byte("dev.tm.noreply")) {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		w.Header().Set("Ce-Specversion", "1.0")
+		w.Header().Set("Ce-Id", "reply-1")
+		w.Header().Set("Ce-Type", "dev.tm.reply")
+		w.Header().Set("Ce-Source", "test-server")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"reply":true}`))
+	}))
+	defer server.Close()
+
+	dataFile, err := ioutil.TempFile("", "event-*.json")
+	require.NoError(t, err)
+	defer os.Remove(dataFile.Name())
+	_, err = dataFile.WriteString(`{"foo":"bar"}`)
+	require.NoError(t, err)
+	require.NoError(t, dataFile.Close())
+
+	clientset := fake.NewConfigSet()
+	e := &Event{
+		Target: server.URL,
+		Type:   "dev.tm.test",
+		Source: "tm",
+		Data:   "@" + dataFile.Name(),
+	}
+	output := new(bytes.Buffer)
+	require.NoError(t, e.Send(clientset, output))
+	assert.Equal(t, "application/json", contentType)
+	assert.Equal(t, `{"foo":"bar"}`, string(body))
+	assert.Contains(t, output.String(), "type: dev.tm.reply")
+	assert.Contains(t, output.String(), `"reply": true`)
+
+	e.Structured = true
+	e.Type = "dev.tm.noreply"
+	output.Reset()
+	require.NoError(t, e.Send(clientset, output))
+	assert.Equal(t, "application/cloudevents+json", contentType)
+	assert.Contains(t, string(body), `"type":"dev.tm.noreply"`)
+	assert.Equal(t, "Event accepted, no reply\n", output.String())
+}
+
+func TestSendCount(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1)%2 == 0 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	e := &Event{
+		Target: server.URL,
+		Type:   "dev.tm.test",
+		Source: "tm",
+		Count:  4,
+		Rate:   100,
+	}
+	output := new(bytes.Buffer)
+	assert.EqualError(t, e.Send(fake.NewConfigSet(), output), "2 of 4 events were not delivered")
+	assert.Contains(t, output.String(), "Sent: 4, failed: 2")
+	assert.Equal(t, int32(4), requests)
+}
+
+func TestSendInvalidRate(t *testing.T) {
+	for _, rate := range []float64{-1, math.NaN()} {
+		e := &Event{Target: "http://localhost", Type: "dev.tm.test", Source: "tm", Count: 2, Rate: rate}
+		assert.Error(t, e.Send(fake.NewConfigSet(), new(bytes.Buffer)))
+	}
+}
+
+func TestInterval(t *testing.T) {
+	testCases := []struct {
+		rate     float64
+		interval time.Duration
+	}{
+		{rate: 1, interval: time.Second},
+		{rate: 4, interval: 250 * time.Millisecond},
+		{rate: 1e9, interval: time.Nanosecond},
+		{rate: 1e12, interval: time.Nanosecond},
+		{rate: math.Inf(1), interval: time.Nanosecond},
+		{rate: 1e-12, interval: math.MaxInt64},
+	}
+	for _, tc := range testCases {
+		e := &Event{Rate: tc.rate}
+		assert.Equal(t, tc.interval, e.interval(), "rate %v", tc.rate)
+	}
+}
+
+func TestTargetURL(t *testing.T) {
+	service := &servingv1.Service{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: fake.Namespace}}
+	service.Status.URL = apis.HTTP("foo.example.com")
+	broker := &eventingv1beta1.Broker{ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: fake.Namespace}}
+	broker.Status.Address.URL = apis.HTTP("broker-ingress.knative-eventing.svc.cluster.local")
+	notReady := &servingv1.Service{ObjectMeta: metav1.ObjectMeta{Name: "bar", Namespace: fake.Namespace}}
+	clientset := fake.NewConfigSet(service, broker, notReady)
+
+	testCases := []struct {
+		target  string
+		url     string
+		wantErr bool
+	}{
+		{target: "service/foo", url: "http://foo.example.com"},
+		{target: "broker/default", url: "http://broker-ingress.knative-eventing.svc.cluster.local"},
+		{target: "https://example.com/events", url: "https://example.com/events"},
+		{target: "service/bar", wantErr: true},
+		{target: "service/missing", wantErr: true},
+		{target: "channel/foo", wantErr: true},
+		{target: "foo", wantErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.target, func(t *testing.T) {
+			e := &Event{Target: tc.target, Namespace: fake.Namespace}
+			url, err := e.targetURL(clientset)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.url, url)
+		})
+	}
+}